@@ -0,0 +1,158 @@
+-- Schema for the URL service database (hcaas_db).
+-- Statements are idempotent so the file can also be replayed against an
+-- existing database to pick up new columns and tables.
+
+CREATE TABLE IF NOT EXISTS urls (
+    id          TEXT PRIMARY KEY,
+    user_id     TEXT        NOT NULL,
+    address     TEXT        NOT NULL,
+    status      TEXT        NOT NULL DEFAULT 'unknown',
+    checked_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_urls_user_id ON urls (user_id);
+
+-- Per-URL check spec
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS method      TEXT    NOT NULL DEFAULT 'GET';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS headers     JSONB;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS body        TEXT    NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS timeout_ms  INTEGER NOT NULL DEFAULT 10000;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS interval_ms INTEGER NOT NULL DEFAULT 60000;
+
+-- Response assertions
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS assertions JSONB;
+
+-- Check history: one row per check run
+CREATE TABLE IF NOT EXISTS check_results (
+    id                BIGSERIAL PRIMARY KEY,
+    url_id            TEXT        NOT NULL REFERENCES urls (id) ON DELETE CASCADE,
+    checked_at        TIMESTAMPTZ NOT NULL,
+    status            TEXT        NOT NULL,
+    status_code       INTEGER     NOT NULL DEFAULT 0,
+    latency_ms        INTEGER     NOT NULL DEFAULT 0,
+    error             TEXT        NOT NULL DEFAULT '',
+    error_class       TEXT        NOT NULL DEFAULT '',
+    failed_assertions JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_check_results_url_checked_at
+    ON check_results (url_id, checked_at DESC, id DESC);
+
+-- When the status last changed, used for downtime on recovery
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- Confirmation thresholds, flap detection and the checker state behind them
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS failure_threshold  INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS recovery_threshold INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS flap_threshold     INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS flap_window_ms     INTEGER NOT NULL DEFAULT 1800000;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS check_state        JSONB   NOT NULL DEFAULT '{}';
+
+-- The checker scheduler syncs incrementally on updated_at
+CREATE INDEX IF NOT EXISTS idx_urls_updated_at ON urls (updated_at);
+
+-- Checker coordination: URLs are hashed into shards, each leased to one live replica
+CREATE TABLE IF NOT EXISTS checker_instances (
+    id           TEXT PRIMARY KEY,
+    heartbeat_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS checker_leases (
+    shard      INTEGER PRIMARY KEY,
+    owner      TEXT,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- Monitor type and per-type settings
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS type TEXT NOT NULL DEFAULT 'http';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS tcp  JSONB;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS dns  JSONB;
+
+-- TLS monitors and the certificate seen by the latest check
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS tls  JSONB;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS cert JSONB;
+ALTER TABLE check_results ADD COLUMN IF NOT EXISTS cert JSONB;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS grpc JSONB;
+
+-- Heartbeat monitors are looked up by the token in their ping URL
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS heartbeat JSONB;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_urls_heartbeat_token ON urls ((heartbeat ->> 'token'))
+    WHERE heartbeat IS NOT NULL;
+
+-- Transaction monitors and the per-step outcome of each run
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS transaction JSONB;
+ALTER TABLE check_results ADD COLUMN IF NOT EXISTS steps JSONB;
+ALTER TABLE check_results ADD COLUMN IF NOT EXISTS failed_step TEXT NOT NULL DEFAULT '';
+
+-- Per-phase timings of HTTP checks
+ALTER TABLE check_results ADD COLUMN IF NOT EXISTS timings JSONB;
+
+-- Content change detection: settings, the accepted baseline and the pending change
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS content          JSONB;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS content_baseline JSONB;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS content_changed  JSONB;
+ALTER TABLE check_results ADD COLUMN IF NOT EXISTS content_hash TEXT NOT NULL DEFAULT '';
+
+-- Stored check credentials. The secret is encrypted with its own data key
+-- (secret_key), which is encrypted with the master key from CREDENTIALS_KEY.
+CREATE TABLE IF NOT EXISTS credentials (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT        NOT NULL,
+    name       TEXT        NOT NULL,
+    type       TEXT        NOT NULL,
+    username   TEXT        NOT NULL DEFAULT '',
+    header     TEXT        NOT NULL DEFAULT '',
+    token_url  TEXT        NOT NULL DEFAULT '',
+    client_id  TEXT        NOT NULL DEFAULT '',
+    scopes     TEXT[],
+    secret_key BYTEA       NOT NULL,
+    secret     BYTEA       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (user_id, name)
+);
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS credential_id TEXT REFERENCES credentials (id);
+
+-- Per-monitor transport settings and client certificate credentials
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS transport JSONB;
+ALTER TABLE credentials ADD COLUMN IF NOT EXISTS certificate TEXT NOT NULL DEFAULT '';
+-- The client certificate sits inside transport; this column gives it a foreign
+-- key, so a certificate can't be deleted while monitors present it
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS client_cert_id TEXT
+    GENERATED ALWAYS AS (transport ->> 'client_cert_id') STORED REFERENCES credentials (id);
+
+-- Retries within a check run, and how many attempts each check took
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS retry JSONB;
+ALTER TABLE check_results ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 1;
+
+-- Paused monitors aren't checked; deleted ones are kept until purged so they can be restored
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS paused     BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Listing filters, sorting and search
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS name       TEXT   NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS tags       TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS latency_ms BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_urls_tags ON urls USING GIN (tags);
+CREATE INDEX IF NOT EXISTS idx_urls_user_created ON urls (user_id, created_at, id) WHERE deleted_at IS NULL;
+
+-- Free-form labels and named groups
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS labels     JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS group_name TEXT  NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_urls_labels ON urls USING GIN (labels);
+CREATE INDEX IF NOT EXISTS idx_urls_user_group ON urls (user_id, group_name) WHERE deleted_at IS NULL;
+
+-- Declarative sync: the stable key a monitor is matched by, whether sync manages
+-- it, and whether it was edited by hand since
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS external_key TEXT    NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS managed      BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS drifted      BOOLEAN NOT NULL DEFAULT false;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_urls_user_key ON urls (user_id, external_key)
+    WHERE deleted_at IS NULL AND external_key <> '';
+
+-- Heartbeat monitors: when the last real ping arrived, or the monitor was
+-- resumed; missed-heartbeat checks don't touch it
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS last_ping_at TIMESTAMPTZ;
@@ -10,6 +10,18 @@ type Notification struct {
 	Status    string    `json:"status" db:"status"` // pending, sent, failed
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Transition details sent by the url service with url_down / url_recovered
+	PreviousStatus  string `json:"previous_status,omitempty" db:"-"`
+	DowntimeSeconds int64  `json:"downtime_seconds,omitempty" db:"-"`
+
+	// Unified diff sent with content_changed
+	Diff string `json:"diff,omitempty" db:"-"`
+
+	// The monitor's name, group and labels, sent with every notification
+	Name   string            `json:"name,omitempty" db:"-"`
+	Group  string            `json:"group,omitempty" db:"-"`
+	Labels map[string]string `json:"labels,omitempty" db:"-"`
 }
 
 const (
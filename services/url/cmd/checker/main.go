@@ -0,0 +1,124 @@
+// Command checker runs only the check pipeline: it schedules and probes URLs,
+// records results and publishes notifications. Run any number of replicas next
+// to the API (started with CHECKER_ENABLED=false); shards are split between them.
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/samims/hcaas/services/url/internal/checker"
+	"github.com/samims/hcaas/services/url/internal/config"
+	"github.com/samims/hcaas/services/url/internal/handler"
+	"github.com/samims/hcaas/services/url/internal/kafka"
+	"github.com/samims/hcaas/services/url/internal/logger"
+	"github.com/samims/hcaas/services/url/internal/metrics"
+	"github.com/samims/hcaas/services/url/internal/router"
+	"github.com/samims/hcaas/services/url/internal/secret"
+	"github.com/samims/hcaas/services/url/internal/service"
+	"github.com/samims/hcaas/services/url/internal/storage"
+)
+
+func main() {
+	l := logger.NewLogger().With("app", "checker")
+	slog.SetDefault(l)
+
+	metrics.Init()
+
+	if err := godotenv.Load(); err != nil {
+		l.Error("Error loading .env file", "err", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		l.Error("Failed to load config", "err", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	dbPool, err := storage.NewPostgresPool(ctx)
+	if err != nil {
+		l.Error("Failed to connect to database", "err", err)
+		os.Exit(1)
+	}
+	defer dbPool.Close()
+
+	ps := storage.NewPostgresStorage(dbPool)
+	urlSvc := service.NewURLService(ps, l)
+	envelope, err := secret.NewEnvelope(cfg.CredentialsConfig.Key)
+	if err != nil {
+		l.Error("Failed to set up credential encryption", "err", err)
+		os.Exit(1)
+	}
+	credentialSvc := service.NewCredentialService(ps, envelope, l)
+	healthSvc := service.NewHealthService(ps, l)
+
+	kafkaAsyncProducer, err := kafka.NewAsyncProducer(cfg.KafkaConfig.Brokers, "url-checker-producer")
+	if err != nil {
+		l.Error("Failed to create sarama producer", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	var wg sync.WaitGroup
+	notificationProducer := kafka.NewProducer(kafkaAsyncProducer, cfg.KafkaConfig.NotificationTopic, cfg.KafkaConfig.NotificationRoutes, l, &wg)
+	notificationProducer.Start(ctx)
+
+	// Per-URL timeouts are applied on each request context, so the client itself has none.
+	httpClient := &http.Client{}
+	leaseStorage := storage.NewPostgresLeaseStorage(dbPool)
+	chkr := checker.NewURLChecker(urlSvc, credentialSvc, l, httpClient, cfg.CheckerConfig, notificationProducer, leaseStorage)
+
+	checkerDone := make(chan struct{})
+	go func() {
+		defer close(checkerDone)
+		chkr.Start(ctx)
+	}()
+
+	server := &http.Server{
+		Addr:    cfg.CheckerConfig.HTTPAddr,
+		Handler: router.NewCheckerRouter(handler.NewHealthHandler(healthSvc, l)),
+	}
+	go func() {
+		l.Info("Health server started", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			l.Error("Failed to start health server", "err", err)
+			os.Exit(1)
+		}
+	}()
+
+	// Graceful shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	l.Info("Shutting down checker...")
+	stop()
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// In-flight checks finish and shard leases are released before exiting
+	select {
+	case <-checkerDone:
+	case <-ctxTimeout.Done():
+		l.Warn("Checker did not stop in time")
+	}
+	notificationProducer.Close(ctxTimeout)
+
+	if err := server.Shutdown(ctxTimeout); err != nil {
+		l.Error("Shutdown failed", "err", err)
+	} else {
+		l.Info("Checker exited cleanly")
+	}
+}
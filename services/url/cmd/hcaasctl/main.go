@@ -0,0 +1,196 @@
+// Command hcaasctl manages monitors declaratively: it syncs a JSON or YAML
+// document kept in a repository with the url service, and exports the current
+// monitors to start such a document from.
+//
+//	hcaasctl sync [-dry-run] monitors.yaml
+//	hcaasctl export [-format yaml] > monitors.yaml
+//
+// The service is reached at HCAAS_URL (or -server) with the bearer token in
+// HCAAS_TOKEN (or -token).
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+const usage = `usage: hcaasctl [-server URL] [-token TOKEN] <command> [args]
+
+commands:
+  sync [-dry-run] FILE   make the managed monitors match FILE (JSON or YAML)
+  export [-format F]     print your monitors as json, yaml or csv
+`
+
+func main() {
+	flags := flag.NewFlagSet("hcaasctl", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	server := flags.String("server", envOr("HCAAS_URL", "http://localhost:8080"), "url service address")
+	token := flags.String("token", os.Getenv("HCAAS_TOKEN"), "bearer token")
+	flags.Parse(os.Args[1:])
+
+	c := &client{
+		server: strings.TrimSuffix(*server, "/"),
+		token:  *token,
+		http:   &http.Client{Timeout: time.Minute},
+	}
+
+	var err error
+	switch flags.Arg(0) {
+	case "sync":
+		err = c.sync(flags.Args()[1:])
+	case "export":
+		err = c.export(flags.Args()[1:])
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "hcaasctl:", err)
+		os.Exit(1)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+type client struct {
+	server string
+	token  string
+	http   *http.Client
+}
+
+// do sends a request to the url service and returns the response body of a
+// 2xx answer. A 400 with a JSON body is returned too, along with an error, as
+// it carries the plan of a rejected sync.
+func (c *client) do(method, path string, query url.Values, contentType string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequest(method, c.server+path+"?"+query.Encode(), body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return data, nil
+	}
+	err = fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	if resp.StatusCode == http.StatusBadRequest && strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return data, err
+	}
+	return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(data)))
+}
+
+func (c *client) sync(args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "only print the plan")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("sync takes one file")
+	}
+	file := flags.Arg(0)
+
+	contentType := "application/json"
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		contentType = "application/yaml"
+	case ".json":
+	default:
+		return fmt.Errorf("%s: sync documents are .json, .yaml or .yml files", file)
+	}
+	doc, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	if *dryRun {
+		query.Set("dry_run", "true")
+	}
+	data, reqErr := c.do(http.MethodPost, "/urls/sync", query, contentType, bytes.NewReader(doc))
+	if data == nil {
+		return reqErr
+	}
+	var plan model.SyncPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return fmt.Errorf("unexpected response: %w", err)
+	}
+	printPlan(os.Stdout, plan)
+	return reqErr
+}
+
+// planSymbols prefix each change in the printed plan
+var planSymbols = map[string]string{
+	model.SyncCreate:    "+",
+	model.SyncUpdate:    "~",
+	model.SyncAdopt:     "~",
+	model.SyncDelete:    "-",
+	model.SyncUnchanged: "=",
+	model.SyncInvalid:   "!",
+}
+
+func printPlan(w io.Writer, plan model.SyncPlan) {
+	for _, change := range plan.Changes {
+		line := fmt.Sprintf("%s %-9s %s  %s", planSymbols[change.Action], change.Action, change.Key, change.Address)
+		if len(change.Fields) > 0 {
+			line += "  (" + strings.Join(change.Fields, ", ") + ")"
+		}
+		if change.Drifted {
+			line += "  [edited by hand since the last sync]"
+		}
+		if change.Error != "" {
+			line += ": " + change.Error
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	verb := "applied"
+	if plan.DryRun {
+		verb = "plan (nothing written)"
+	}
+	if plan.Invalid > 0 {
+		verb = "rejected"
+	}
+	fmt.Fprintf(w, "\n%s: %d created, %d updated, %d deleted, %d unchanged, %d invalid\n",
+		verb, plan.Created, plan.Updated, plan.Deleted, plan.Unchanged, plan.Invalid)
+}
+
+func (c *client) export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "yaml", "json, yaml or csv")
+	flags.Parse(args)
+
+	data, err := c.do(http.MethodGet, "/urls/export", url.Values{"format": {*format}}, "", nil)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
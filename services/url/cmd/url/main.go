@@ -10,15 +10,16 @@ import (
 	"sync"
 	"time"
 
-	"github.com/IBM/sarama"
 	"github.com/joho/godotenv"
 
 	"github.com/samims/hcaas/services/url/internal/checker"
+	"github.com/samims/hcaas/services/url/internal/config"
 	"github.com/samims/hcaas/services/url/internal/handler"
 	"github.com/samims/hcaas/services/url/internal/kafka"
 	"github.com/samims/hcaas/services/url/internal/logger"
 	"github.com/samims/hcaas/services/url/internal/metrics"
 	"github.com/samims/hcaas/services/url/internal/router"
+	"github.com/samims/hcaas/services/url/internal/secret"
 	"github.com/samims/hcaas/services/url/internal/service"
 	"github.com/samims/hcaas/services/url/internal/storage"
 )
@@ -33,7 +34,14 @@ func main() {
 		l.Error("Error loading .env file", "err", err)
 	}
 
-	ctx := context.Background()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		l.Error("Failed to load config", "err", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
 	dbPool, err := storage.NewPostgresPool(ctx)
 	if err != nil {
 		l.Error("Failed to connect to database", "err", err)
@@ -44,25 +52,16 @@ func main() {
 	// Initialize layers
 	ps := storage.NewPostgresStorage(dbPool)
 	urlSvc := service.NewURLService(ps, l)
-	healthSvc := service.NewHealthService(ps, l)
-
-	// Kafka producers setup
-	// TODO: will move to another place
-	kafkaBrokers := os.Getenv("KAFKA_BROKERS")
-	kafkaNotifTopic := os.Getenv("KAFKA_NOTIF_TOPIC")
-	if kafkaBrokers == "" || kafkaNotifTopic == "" {
-		l.Error("KAFKA_BROKERS or KAFKA_TOPIC not set")
+	envelope, err := secret.NewEnvelope(cfg.CredentialsConfig.Key)
+	if err != nil {
+		l.Error("Failed to set up credential encryption", "err", err)
 		os.Exit(1)
 	}
+	credentialSvc := service.NewCredentialService(ps, envelope, l)
+	healthSvc := service.NewHealthService(ps, l)
 
-	saramaConfig := sarama.NewConfig()
-	saramaConfig.Producer.RequiredAcks = sarama.WaitForAll // Acks from all replicas
-	saramaConfig.Producer.Retry.Max = 5
-	saramaConfig.Producer.Return.Successes = true
-	saramaConfig.ClientID = "url-service-producer"
-
-	kafkaAsyncProducer, err := sarama.NewAsyncProducer([]string{kafkaBrokers}, saramaConfig)
-
+	// Kafka producers setup
+	kafkaAsyncProducer, err := kafka.NewAsyncProducer(cfg.KafkaConfig.Brokers, "url-service-producer")
 	if err != nil {
 		l.Error("Failed to create sarama producer", slog.Any("error", err))
 		os.Exit(1)
@@ -70,25 +69,34 @@ func main() {
 
 	var wg sync.WaitGroup
 
-	l.Info("Before NewProducer")
-	notificationProducer := kafka.NewProducer(kafkaAsyncProducer, kafkaNotifTopic, l, &wg)
-	l.Info("After NewProducer")
-
-	l.Info("Calling notificationProducer.Start()")
-
+	notificationProducer := kafka.NewProducer(kafkaAsyncProducer, cfg.KafkaConfig.NotificationTopic, cfg.KafkaConfig.NotificationRoutes, l, &wg)
 	notificationProducer.Start(ctx)
 
-	httpClient := &http.Client{Timeout: 5 * time.Second}
-	chkr := checker.NewURLChecker(urlSvc, l, httpClient, 1*time.Minute, notificationProducer)
-	go chkr.Start(ctx)
+	// The checker can run in-process or as the separate cmd/checker binary
+	checkerDone := make(chan struct{})
+	if cfg.CheckerConfig.Enabled {
+		// Per-URL timeouts are applied on each request context, so the client itself has none.
+		httpClient := &http.Client{}
+		leaseStorage := storage.NewPostgresLeaseStorage(dbPool)
+		chkr := checker.NewURLChecker(urlSvc, credentialSvc, l, httpClient, cfg.CheckerConfig, notificationProducer, leaseStorage)
+		go func() {
+			defer close(checkerDone)
+			chkr.Start(ctx)
+		}()
+	} else {
+		l.Info("Checker disabled; run cmd/checker to perform checks")
+		close(checkerDone)
+	}
 
 	urlHandler := handler.NewURLHandler(urlSvc, l)
 	healthHandler := handler.NewHealthHandler(healthSvc, l)
+	heartbeatHandler := handler.NewHeartbeatHandler(checker.NewHeartbeatReceiver(urlSvc, notificationProducer, l), l)
+	credentialHandler := handler.NewCredentialHandler(credentialSvc, l)
 
 	// Setup router and server
 	port := ":8080"
 
-	r := router.NewRouter(urlHandler, healthHandler, l)
+	r := router.NewRouter(urlHandler, healthHandler, heartbeatHandler, credentialHandler, l)
 
 	server := &http.Server{
 		Addr:    port,
@@ -119,4 +127,13 @@ func main() {
 	} else {
 		l.Info("Server exited cleanly")
 	}
+
+	// Stop the checker so it hands its shard leases back
+	stop()
+	select {
+	case <-checkerDone:
+	case <-ctxTimeout.Done():
+		l.Warn("Checker did not stop in time")
+	}
+	notificationProducer.Close(ctxTimeout)
 }
@@ -0,0 +1,277 @@
+package assertion
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// Response is the part of a check response assertions are evaluated against
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Duration   time.Duration
+}
+
+// Assertion checks a single condition and returns an error describing the failure
+type Assertion interface {
+	Check(resp Response) error
+}
+
+// Factory builds an Assertion from its configuration, validating it on the way
+type Factory func(spec model.Assertion) (Assertion, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register makes an assertion type available to Compile. Registering the same type twice panics.
+func Register(assertionType string, f Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := factories[assertionType]; dup {
+		panic("assertion: Register called twice for type " + assertionType)
+	}
+	factories[assertionType] = f
+}
+
+func init() {
+	Register(model.AssertStatusCode, newStatusCode)
+	Register(model.AssertBodyContains, newBodyContains)
+	Register(model.AssertBodyRegex, newBodyRegex)
+	Register(model.AssertJSONPath, newJSONPath)
+	Register(model.AssertHeader, newHeader)
+	Register(model.AssertNoHeader, newNoHeader)
+	Register(model.AssertResponseTime, newResponseTime)
+}
+
+// Set is a compiled list of assertions for one URL
+type Set struct {
+	assertions []Assertion
+	hasStatus  bool
+}
+
+// Compile validates the specs and builds the assertions.
+// Without an explicit status_code assertion any status below 400 is accepted.
+func Compile(specs []model.Assertion) (*Set, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	set := &Set{}
+	for i, spec := range specs {
+		f, ok := factories[spec.Type]
+		if !ok {
+			return nil, fmt.Errorf("assertion %d: unknown type %q", i, spec.Type)
+		}
+		a, err := f(spec)
+		if err != nil {
+			return nil, fmt.Errorf("assertion %d (%s): %w", i, spec.Type, err)
+		}
+		if spec.Type == model.AssertStatusCode {
+			set.hasStatus = true
+		}
+		set.assertions = append(set.assertions, a)
+	}
+	return set, nil
+}
+
+// Evaluate runs every assertion and returns the failure reasons, empty when all passed
+func (s *Set) Evaluate(resp Response) []string {
+	var failures []string
+	if !s.hasStatus && resp.StatusCode >= http.StatusBadRequest {
+		failures = append(failures, fmt.Sprintf("status code %d is not below 400", resp.StatusCode))
+	}
+	for _, a := range s.assertions {
+		if err := a.Check(resp); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	return failures
+}
+
+// NeedsBody reports whether any assertion inspects the response body
+func NeedsBody(specs []model.Assertion) bool {
+	for _, spec := range specs {
+		switch spec.Type {
+		case model.AssertBodyContains, model.AssertBodyRegex, model.AssertJSONPath:
+			return true
+		}
+	}
+	return false
+}
+
+// statusRange is an inclusive range of HTTP status codes
+type statusRange struct{ lo, hi int }
+
+type statusCode struct {
+	ranges []statusRange
+	raw    string
+}
+
+func newStatusCode(spec model.Assertion) (Assertion, error) {
+	if len(spec.Values) == 0 {
+		return nil, fmt.Errorf("values must list at least one status code or range")
+	}
+	a := &statusCode{raw: strings.Join(spec.Values, ",")}
+	for _, v := range spec.Values {
+		r, err := parseStatusRange(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		a.ranges = append(a.ranges, r)
+	}
+	return a, nil
+}
+
+// parseStatusRange accepts "200", "2xx" and "200-299"
+func parseStatusRange(v string) (statusRange, error) {
+	if len(v) == 3 && strings.HasSuffix(strings.ToLower(v), "xx") {
+		class, err := strconv.Atoi(v[:1])
+		if err != nil || class < 1 || class > 5 {
+			return statusRange{}, fmt.Errorf("invalid status class %q", v)
+		}
+		return statusRange{class * 100, class*100 + 99}, nil
+	}
+	if lo, hi, ok := strings.Cut(v, "-"); ok {
+		l, err1 := strconv.Atoi(strings.TrimSpace(lo))
+		h, err2 := strconv.Atoi(strings.TrimSpace(hi))
+		if err1 != nil || err2 != nil || l > h || l < 100 || h > 599 {
+			return statusRange{}, fmt.Errorf("invalid status range %q", v)
+		}
+		return statusRange{l, h}, nil
+	}
+	code, err := strconv.Atoi(v)
+	if err != nil || code < 100 || code > 599 {
+		return statusRange{}, fmt.Errorf("invalid status code %q", v)
+	}
+	return statusRange{code, code}, nil
+}
+
+func (a *statusCode) Check(resp Response) error {
+	for _, r := range a.ranges {
+		if resp.StatusCode >= r.lo && resp.StatusCode <= r.hi {
+			return nil
+		}
+	}
+	return fmt.Errorf("status code %d not in [%s]", resp.StatusCode, a.raw)
+}
+
+type bodyContains struct {
+	value  string
+	negate bool
+}
+
+func newBodyContains(spec model.Assertion) (Assertion, error) {
+	if spec.Value == "" {
+		return nil, fmt.Errorf("value must not be empty")
+	}
+	return &bodyContains{value: spec.Value, negate: spec.Negate}, nil
+}
+
+func (a *bodyContains) Check(resp Response) error {
+	found := bytes.Contains(resp.Body, []byte(a.value))
+	switch {
+	case a.negate && found:
+		return fmt.Errorf("body contains %q", a.value)
+	case !a.negate && !found:
+		return fmt.Errorf("body does not contain %q", a.value)
+	}
+	return nil
+}
+
+type bodyRegex struct {
+	re     *regexp.Regexp
+	negate bool
+}
+
+func newBodyRegex(spec model.Assertion) (Assertion, error) {
+	re, err := regexp.Compile(spec.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	return &bodyRegex{re: re, negate: spec.Negate}, nil
+}
+
+func (a *bodyRegex) Check(resp Response) error {
+	matched := a.re.Match(resp.Body)
+	switch {
+	case a.negate && matched:
+		return fmt.Errorf("body matches /%s/", a.re)
+	case !a.negate && !matched:
+		return fmt.Errorf("body does not match /%s/", a.re)
+	}
+	return nil
+}
+
+type header struct {
+	name  string
+	value string
+}
+
+func newHeader(spec model.Assertion) (Assertion, error) {
+	if spec.Target == "" {
+		return nil, fmt.Errorf("target header name must not be empty")
+	}
+	return &header{name: http.CanonicalHeaderKey(spec.Target), value: spec.Value}, nil
+}
+
+func (a *header) Check(resp Response) error {
+	values, ok := resp.Header[a.name]
+	if !ok {
+		return fmt.Errorf("header %s is missing", a.name)
+	}
+	if a.value == "" {
+		return nil
+	}
+	for _, v := range values {
+		if v == a.value {
+			return nil
+		}
+	}
+	return fmt.Errorf("header %s is %q, want %q", a.name, strings.Join(values, ", "), a.value)
+}
+
+type noHeader struct {
+	name string
+}
+
+func newNoHeader(spec model.Assertion) (Assertion, error) {
+	if spec.Target == "" {
+		return nil, fmt.Errorf("target header name must not be empty")
+	}
+	return &noHeader{name: http.CanonicalHeaderKey(spec.Target)}, nil
+}
+
+func (a *noHeader) Check(resp Response) error {
+	if _, ok := resp.Header[a.name]; ok {
+		return fmt.Errorf("header %s must not be present", a.name)
+	}
+	return nil
+}
+
+type responseTime struct {
+	max time.Duration
+}
+
+func newResponseTime(spec model.Assertion) (Assertion, error) {
+	if spec.Max <= 0 {
+		return nil, fmt.Errorf("max must be a positive duration")
+	}
+	return &responseTime{max: spec.Max.Std()}, nil
+}
+
+func (a *responseTime) Check(resp Response) error {
+	if resp.Duration > a.max {
+		return fmt.Errorf("response time %s exceeds %s", resp.Duration.Round(time.Millisecond), a.max)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package assertion
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// Test_Set_Evaluate tests assertion evaluation against canned responses.
+// Table Driven Test Pattern used
+func Test_Set_Evaluate(t *testing.T) {
+	resp := Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       []byte(`{"status":"degraded","checks":[{"name":"db","ok":true}]}`),
+		Duration:   120 * time.Millisecond,
+	}
+
+	tests := []struct {
+		name  string
+		specs []model.Assertion
+		resp  Response
+		want  []string
+	}{
+		{
+			name: "default accepts status below 400",
+			resp: resp,
+		},
+		{
+			name: "default rejects 503",
+			resp: Response{StatusCode: http.StatusServiceUnavailable},
+			want: []string{"status code 503 is not below 400"},
+		},
+		{
+			name:  "explicit status set overrides default",
+			specs: []model.Assertion{{Type: model.AssertStatusCode, Values: []string{"503"}}},
+			resp:  Response{StatusCode: http.StatusServiceUnavailable},
+		},
+		{
+			name:  "status range mismatch",
+			specs: []model.Assertion{{Type: model.AssertStatusCode, Values: []string{"201-204", "3xx"}}},
+			resp:  resp,
+			want:  []string{"status code 200 not in [201-204,3xx]"},
+		},
+		{
+			name:  "json path catches degraded",
+			specs: []model.Assertion{{Type: model.AssertJSONPath, Target: "$.status", Value: "ok"}},
+			resp:  resp,
+			want:  []string{`$.status is "degraded", want "ok"`},
+		},
+		{
+			name:  "json path into array",
+			specs: []model.Assertion{{Type: model.AssertJSONPath, Target: "$.checks[0].ok", Value: "true"}},
+			resp:  resp,
+		},
+		{
+			name: "body substring and regex",
+			specs: []model.Assertion{
+				{Type: model.AssertBodyContains, Value: "degraded", Negate: true},
+				{Type: model.AssertBodyRegex, Value: `"name":"db"`},
+			},
+			resp: resp,
+			want: []string{`body contains "degraded"`},
+		},
+		{
+			name: "required and forbidden headers",
+			specs: []model.Assertion{
+				{Type: model.AssertHeader, Target: "content-type", Value: "application/json"},
+				{Type: model.AssertNoHeader, Target: "X-Debug"},
+				{Type: model.AssertHeader, Target: "X-Request-Id"},
+			},
+			resp: resp,
+			want: []string{"header X-Request-Id is missing"},
+		},
+		{
+			name:  "response time",
+			specs: []model.Assertion{{Type: model.AssertResponseTime, Max: model.Duration(100 * time.Millisecond)}},
+			resp:  resp,
+			want:  []string{"response time 120ms exceeds 100ms"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := Compile(tt.specs)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if got := set.Evaluate(tt.resp); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Compile_invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec model.Assertion
+	}{
+		{"unknown type", model.Assertion{Type: "telepathy"}},
+		{"bad status code", model.Assertion{Type: model.AssertStatusCode, Values: []string{"2000"}}},
+		{"empty status set", model.Assertion{Type: model.AssertStatusCode}},
+		{"bad regex", model.Assertion{Type: model.AssertBodyRegex, Value: "("}},
+		{"bad json path", model.Assertion{Type: model.AssertJSONPath, Target: "$.a[x]"}},
+		{"missing header name", model.Assertion{Type: model.AssertHeader}},
+		{"missing max", model.Assertion{Type: model.AssertResponseTime}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile([]model.Assertion{tt.spec}); err == nil {
+				t.Errorf("Compile() expected error")
+			}
+		})
+	}
+}
+
+func Test_Render(t *testing.T) {
+	vars := map[string]string{"token": "abc", "id": "7"}
+
+	got, err := Render("/orders/{{id}}?t={{ token }}", vars)
+	if err != nil || got != "/orders/7?t=abc" {
+		t.Errorf("Render() = %q, %v; want %q", got, err, "/orders/7?t=abc")
+	}
+	if _, err := Render("{{missing}}", vars); err == nil {
+		t.Error("Render() with an undefined variable succeeded, want error")
+	}
+	if refs := References("{{a}} and {{ b }} but not {{1c}}"); !reflect.DeepEqual(refs, []string{"a", "b"}) {
+		t.Errorf("References() = %v, want [a b]", refs)
+	}
+}
@@ -0,0 +1,112 @@
+package assertion
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// variableName is what an extraction may be called and referenced as
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// templateRef matches {{name}} references, with optional inner spaces
+var templateRef = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// Extractor pulls one named value out of a response
+type Extractor struct {
+	spec model.Extraction
+	re   *regexp.Regexp
+}
+
+// CompileExtraction validates the spec and builds its Extractor
+func CompileExtraction(spec model.Extraction) (*Extractor, error) {
+	if !variableName.MatchString(spec.Name) {
+		return nil, fmt.Errorf("extraction name %q must be letters, digits and underscores", spec.Name)
+	}
+	if spec.Target == "" {
+		return nil, fmt.Errorf("extraction %q: target must not be empty", spec.Name)
+	}
+	e := &Extractor{spec: spec}
+	switch spec.From {
+	case model.ExtractJSONPath:
+		if _, err := parsePath(spec.Target); err != nil {
+			return nil, fmt.Errorf("extraction %q: %w", spec.Name, err)
+		}
+	case model.ExtractHeader:
+	case model.ExtractRegex:
+		re, err := regexp.Compile(spec.Target)
+		if err != nil {
+			return nil, fmt.Errorf("extraction %q: invalid regex: %w", spec.Name, err)
+		}
+		e.re = re
+	default:
+		return nil, fmt.Errorf("extraction %q: unknown source %q", spec.Name, spec.From)
+	}
+	return e, nil
+}
+
+// Name is the variable the extracted value is stored as
+func (e *Extractor) Name() string {
+	return e.spec.Name
+}
+
+// Extract returns the value from resp, or an error when it is absent
+func (e *Extractor) Extract(resp Response) (string, error) {
+	switch e.spec.From {
+	case model.ExtractJSONPath:
+		return ExtractJSON(resp.Body, e.spec.Target)
+	case model.ExtractHeader:
+		if v := resp.Header.Get(e.spec.Target); v != "" {
+			return v, nil
+		}
+		return "", fmt.Errorf("header %s not present", e.spec.Target)
+	default:
+		m := e.re.FindSubmatch(resp.Body)
+		if m == nil {
+			return "", fmt.Errorf("body does not match /%s/", e.re)
+		}
+		if len(m) > 1 {
+			return string(m[1]), nil
+		}
+		return string(m[0]), nil
+	}
+}
+
+// ExtractionsNeedBody reports whether any extraction reads the response body
+func ExtractionsNeedBody(specs []model.Extraction) bool {
+	for _, spec := range specs {
+		if spec.From != model.ExtractHeader {
+			return true
+		}
+	}
+	return false
+}
+
+// References lists the variable names s refers to as {{name}}
+func References(s string) []string {
+	var names []string
+	for _, m := range templateRef.FindAllStringSubmatch(s, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// Render substitutes {{name}} references in s with values from vars.
+// References to undefined variables are an error.
+func Render(s string, vars map[string]string) (string, error) {
+	var missing []string
+	out := templateRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := templateRef.FindStringSubmatch(ref)[1]
+		v, ok := vars[name]
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined variables: %s", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
@@ -0,0 +1,126 @@
+package assertion
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// pathStep is either an object key or an array index
+type pathStep struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+// parsePath understands the subset of JSONPath needed for health payloads:
+// "$.status", "$.checks[0].state", "data.items[2]".
+func parsePath(path string) ([]pathStep, error) {
+	p := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	p = strings.TrimPrefix(p, ".")
+	if p == "" {
+		return nil, fmt.Errorf("path must not be empty")
+	}
+
+	var steps []pathStep
+	for _, part := range strings.Split(p, ".") {
+		key, rest, _ := strings.Cut(part, "[")
+		if key != "" {
+			steps = append(steps, pathStep{key: key})
+		}
+		for rest != "" {
+			idx, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("unterminated index in %q", path)
+			}
+			n, err := strconv.Atoi(idx)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index %q in %q", idx, path)
+			}
+			steps = append(steps, pathStep{index: n, isIdx: true})
+			rest = strings.TrimPrefix(after, "[")
+			if after != "" && !strings.HasPrefix(after, "[") {
+				return nil, fmt.Errorf("unexpected %q in %q", after, path)
+			}
+		}
+		if key == "" && !strings.Contains(part, "[") {
+			return nil, fmt.Errorf("empty segment in %q", path)
+		}
+	}
+	return steps, nil
+}
+
+// lookup resolves a parsed path against a decoded JSON document
+func lookup(doc interface{}, steps []pathStep) (interface{}, bool) {
+	cur := doc
+	for _, s := range steps {
+		if s.isIdx {
+			arr, ok := cur.([]interface{})
+			if !ok || s.index >= len(arr) {
+				return nil, false
+			}
+			cur = arr[s.index]
+			continue
+		}
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = obj[s.key]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// ExtractJSON returns the value at path in body rendered as a string: strings
+// as-is, everything else as its JSON encoding.
+func ExtractJSON(body []byte, path string) (string, error) {
+	steps, err := parsePath(path)
+	if err != nil {
+		return "", err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return "", fmt.Errorf("body is not valid JSON")
+	}
+	v, ok := lookup(doc, steps)
+	if !ok {
+		return "", fmt.Errorf("%s not found in body", path)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, _ := json.Marshal(v)
+	return string(b), nil
+}
+
+type jsonPath struct {
+	path     string
+	expected string
+	negate   bool
+}
+
+func newJSONPath(spec model.Assertion) (Assertion, error) {
+	if _, err := parsePath(spec.Target); err != nil {
+		return nil, err
+	}
+	return &jsonPath{path: spec.Target, expected: spec.Value, negate: spec.Negate}, nil
+}
+
+func (a *jsonPath) Check(resp Response) error {
+	got, err := ExtractJSON(resp.Body, a.path)
+	if err != nil {
+		return err
+	}
+	switch {
+	case a.negate && got == a.expected:
+		return fmt.Errorf("%s is %q", a.path, got)
+	case !a.negate && got != a.expected:
+		return fmt.Errorf("%s is %q, want %q", a.path, got, a.expected)
+	}
+	return nil
+}
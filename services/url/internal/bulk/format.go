@@ -0,0 +1,348 @@
+// Package bulk reads and writes monitor lists in the import and export
+// formats: JSON, YAML and CSV.
+//
+// Every format is read into one JSON object per monitor, in the shape POST /urls
+// takes, so the service validates imports the same way whatever the format.
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// Supported formats
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+)
+
+// ContentTypes are the media types exports are served with
+var ContentTypes = map[string]string{
+	FormatJSON: "application/json",
+	FormatYAML: "application/yaml",
+	FormatCSV:  "text/csv",
+}
+
+var mediaFormats = map[string]string{
+	"application/json":   FormatJSON,
+	"application/yaml":   FormatYAML,
+	"application/x-yaml": FormatYAML,
+	"text/yaml":          FormatYAML,
+	"text/x-yaml":        FormatYAML,
+	"text/csv":           FormatCSV,
+}
+
+// FormatFor picks the format named explicitly, else the one of the media type.
+// It falls back to JSON when neither is given.
+func FormatFor(name, mediaType string) (string, error) {
+	if name != "" {
+		name = strings.ToLower(name)
+		if name == "yml" {
+			name = FormatYAML
+		}
+		if _, ok := ContentTypes[name]; !ok {
+			return "", fmt.Errorf("unsupported format %q: use json, yaml or csv", name)
+		}
+		return name, nil
+	}
+	if mt, _, err := mime.ParseMediaType(mediaType); err == nil {
+		if format, ok := mediaFormats[mt]; ok {
+			return format, nil
+		}
+	}
+	return FormatJSON, nil
+}
+
+// runtimeFields are the fields of a monitor that describe its state rather
+// than its spec; exports leave them out and imports ignore them
+var runtimeFields = []string{
+	"id", "user_id", "status", "checked_at", "latency", "status_changed_at", "created_at", "updated_at",
+	"paused", "deleted_at", "state", "cert", "managed", "drifted", "last_ping_at",
+}
+
+// Spec is the importable part of a monitor, as the JSON object an import row
+// would give for it
+func Spec(url model.URL) (map[string]any, error) {
+	data, err := json.Marshal(url)
+	if err != nil {
+		return nil, err
+	}
+	var spec map[string]any
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	for _, field := range runtimeFields {
+		delete(spec, field)
+	}
+	return spec, nil
+}
+
+// StripRuntime drops the runtime fields from a monitor object given to an
+// import or sync, so decoding it can't set the status, state or other fields
+// only the server and the checker write
+func StripRuntime(raw json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for _, field := range runtimeFields {
+		delete(fields, field)
+	}
+	return json.Marshal(fields)
+}
+
+// Decode reads a list of monitors, one JSON object per monitor
+func Decode(format string, r io.Reader) ([]json.RawMessage, error) {
+	switch format {
+	case FormatJSON:
+		var rows []json.RawMessage
+		if err := json.NewDecoder(r).Decode(&rows); err != nil {
+			return nil, fmt.Errorf("invalid JSON: expected a list of monitors: %w", err)
+		}
+		return rows, nil
+	case FormatYAML:
+		return decodeYAML(r)
+	case FormatCSV:
+		return decodeCSV(r)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func decodeYAML(r io.Reader) ([]json.RawMessage, error) {
+	var items []any
+	if err := yaml.NewDecoder(r).Decode(&items); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid YAML: expected a list of monitors: %w", err)
+	}
+	rows := make([]json.RawMessage, len(items))
+	for i, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		rows[i] = data
+	}
+	return rows, nil
+}
+
+// DecodeDocument reads a sync document, in JSON or YAML
+func DecodeDocument(format string, r io.Reader) (model.SyncDocument, error) {
+	var doc model.SyncDocument
+	switch format {
+	case FormatJSON:
+		if err := json.NewDecoder(r).Decode(&doc); err != nil {
+			return doc, fmt.Errorf("invalid JSON: %w", err)
+		}
+	case FormatYAML:
+		var v any
+		if err := yaml.NewDecoder(r).Decode(&v); err != nil && err != io.EOF {
+			return doc, fmt.Errorf("invalid YAML: %w", err)
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return doc, fmt.Errorf("invalid YAML: %w", err)
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return doc, fmt.Errorf("invalid sync document: %w", err)
+		}
+	default:
+		return doc, fmt.Errorf("sync documents are JSON or YAML, not %s", format)
+	}
+	return doc, nil
+}
+
+// Encode writes the specs of urls
+func Encode(format string, w io.Writer, urls []model.URL) error {
+	specs := make([]map[string]any, len(urls))
+	for i, url := range urls {
+		spec, err := Spec(url)
+		if err != nil {
+			return err
+		}
+		specs[i] = spec
+	}
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(specs)
+	case FormatYAML:
+		return encodeYAML(w, specs)
+	case FormatCSV:
+		return encodeCSV(w, specs)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// encodeYAML goes through JSON so the fields are named and formatted the same
+// way as in the other formats
+func encodeYAML(w io.Writer, specs []map[string]any) error {
+	data, err := json.Marshal(specs)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	blockStyle(&doc)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle drops the flow style and quoting JSON came with; the encoder
+// quotes strings that need it
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		blockStyle(child)
+	}
+}
+
+// CSVColumns are the fields a CSV import or export carries. Settings that
+// don't fit in a cell, such as assertions or protocol options, are only
+// available in JSON and YAML.
+var CSVColumns = []string{
+	"name", "address", "type", "method", "interval", "timeout",
+	"failure_threshold", "recovery_threshold", "group", "tags", "labels",
+}
+
+var csvIntColumns = map[string]bool{"failure_threshold": true, "recovery_threshold": true}
+
+// decodeCSV reads a header row naming some of CSVColumns, then one monitor
+// per row. Empty cells are left out of the row's object. Tags are separated by
+// ';', and labels are key=value pairs separated by ';'.
+func decodeCSV(r io.Reader) ([]json.RawMessage, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	for i, column := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(column))
+		if !slices.Contains(CSVColumns, header[i]) {
+			return nil, fmt.Errorf("unknown CSV column %q: use %s", column, strings.Join(CSVColumns, ", "))
+		}
+	}
+
+	var rows []json.RawMessage
+	for n := 1; ; n++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		row := map[string]any{}
+		for i, cell := range record {
+			cell = strings.TrimSpace(cell)
+			if cell == "" {
+				continue
+			}
+			column := header[i]
+			switch {
+			case csvIntColumns[column]:
+				v, err := strconv.Atoi(cell)
+				if err != nil {
+					return nil, fmt.Errorf("row %d: %s must be a number", n, column)
+				}
+				row[column] = v
+			case column == "tags":
+				row[column] = strings.Split(cell, ";")
+			case column == "labels":
+				labels := map[string]string{}
+				for _, pair := range strings.Split(cell, ";") {
+					key, value, ok := strings.Cut(pair, "=")
+					if !ok {
+						return nil, fmt.Errorf("row %d: label %q is not key=value", n, pair)
+					}
+					labels[strings.TrimSpace(key)] = strings.TrimSpace(value)
+				}
+				row[column] = labels
+			default:
+				row[column] = cell
+			}
+		}
+		data, err := json.Marshal(row)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", n, err)
+		}
+		rows = append(rows, data)
+	}
+}
+
+func encodeCSV(w io.Writer, specs []map[string]any) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(CSVColumns); err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		record := make([]string, len(CSVColumns))
+		for i, column := range CSVColumns {
+			record[i] = csvCell(spec[column])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		parts := make([]string, len(v))
+		for i, part := range v {
+			parts[i] = csvCell(part)
+		}
+		return strings.Join(parts, ";")
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		var b bytes.Buffer
+		for i, key := range keys {
+			if i > 0 {
+				b.WriteByte(';')
+			}
+			b.WriteString(key + "=" + csvCell(v[key]))
+		}
+		return b.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,254 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// Test_Decode tests reading import rows in each format.
+// Table Driven Test Pattern used
+func Test_Decode(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		input   string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "json",
+			format: FormatJSON,
+			input:  `[{"address": "https://a.example.com", "interval": "30s"}, {"address": "https://b.example.com"}]`,
+			want:   []string{`{"address":"https://a.example.com","interval":"30s"}`, `{"address":"https://b.example.com"}`},
+		},
+		{
+			name:   "yaml",
+			format: FormatYAML,
+			input: `
+- address: https://a.example.com
+  interval: 30s
+  labels:
+    env: prod
+  failure_threshold: 3
+`,
+			want: []string{`{"address":"https://a.example.com","failure_threshold":3,"interval":"30s","labels":{"env":"prod"}}`},
+		},
+		{
+			name:   "empty yaml",
+			format: FormatYAML,
+			input:  "",
+			want:   []string{},
+		},
+		{
+			name:   "csv with empty cells left out",
+			format: FormatCSV,
+			input: `address,name,failure_threshold,tags,labels
+https://a.example.com,Checkout,3,payments;api,env=prod;team=payments
+https://b.example.com,,,,
+`,
+			want: []string{
+				`{"address":"https://a.example.com","failure_threshold":3,"labels":{"env":"prod","team":"payments"},"name":"Checkout","tags":["payments","api"]}`,
+				`{"address":"https://b.example.com"}`,
+			},
+		},
+		{
+			name:    "csv unknown column",
+			format:  FormatCSV,
+			input:   "address,assertions\nhttps://a.example.com,x\n",
+			wantErr: `unknown CSV column "assertions"`,
+		},
+		{
+			name:    "csv bad number",
+			format:  FormatCSV,
+			input:   "address,failure_threshold\nhttps://a.example.com,three\n",
+			wantErr: "row 1: failure_threshold must be a number",
+		},
+		{
+			name:    "json object instead of list",
+			format:  FormatJSON,
+			input:   `{"address": "https://a.example.com"}`,
+			wantErr: "expected a list of monitors",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Decode(tt.format, strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Decode() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("Decode() = %d rows, want %d", len(rows), len(tt.want))
+			}
+			for i, row := range rows {
+				var compact bytes.Buffer
+				if err := json.Compact(&compact, row); err != nil {
+					t.Fatal(err)
+				}
+				if compact.String() != tt.want[i] {
+					t.Errorf("row %d = %s, want %s", i+1, compact.String(), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// Test_EncodeDecode tests that an export reads back as the same specs.
+// Table Driven Test Pattern used
+func Test_EncodeDecode(t *testing.T) {
+	urls := []model.URL{{
+		ID:               "e2c1b7f4",
+		UserID:           "user-1",
+		Name:             "Checkout: API",
+		Address:          "https://pay.example.com/health",
+		Status:           model.StatusHealthy,
+		CheckedAt:        time.Now(),
+		Type:             model.MonitorHTTP,
+		Method:           "GET",
+		Interval:         model.Duration(30 * time.Second),
+		Timeout:          model.Duration(5 * time.Second),
+		FailureThreshold: 3,
+		Group:            "payments",
+		Tags:             []string{"payments", "true"},
+		Labels:           map[string]string{"env": "prod", "tier": "1"},
+	}}
+
+	tests := []struct {
+		format string
+	}{
+		{format: FormatJSON},
+		{format: FormatYAML},
+		{format: FormatCSV},
+	}
+
+	want, err := Spec(urls[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Encode(tt.format, &out, urls); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if strings.Contains(out.String(), "user-1") || strings.Contains(out.String(), "e2c1b7f4") {
+				t.Errorf("export includes runtime fields:\n%s", out.String())
+			}
+			rows, err := Decode(tt.format, &out)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if len(rows) != 1 {
+				t.Fatalf("Decode() = %d rows, want 1", len(rows))
+			}
+
+			var url model.URL
+			if err := json.Unmarshal(rows[0], &url); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Spec(url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(want)
+			if !bytes.Equal(gotJSON, wantJSON) {
+				t.Errorf("round trip = %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+// Test_DecodeDocument tests reading sync documents.
+// Table Driven Test Pattern used
+func Test_DecodeDocument(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       string
+		input        string
+		wantGroup    string
+		wantMonitors int
+		wantNil      bool
+		wantErr      bool
+	}{
+		{
+			name:   "yaml",
+			format: FormatYAML,
+			input: `
+group: payments
+monitors:
+  - key: checkout
+    address: https://pay.example.com/health
+  - key: webhooks
+    address: https://pay.example.com/webhooks
+`,
+			wantGroup:    "payments",
+			wantMonitors: 2,
+		},
+		{
+			name:         "json with an empty list",
+			format:       FormatJSON,
+			input:        `{"monitors": []}`,
+			wantMonitors: 0,
+		},
+		{
+			name:    "empty yaml has no list",
+			format:  FormatYAML,
+			input:   "",
+			wantNil: true,
+		},
+		{
+			name:    "csv",
+			format:  FormatCSV,
+			input:   "key,address\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := DecodeDocument(tt.format, strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeDocument() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if doc.Group != tt.wantGroup {
+				t.Errorf("Group = %q, want %q", doc.Group, tt.wantGroup)
+			}
+			if (doc.Monitors == nil) != tt.wantNil {
+				t.Errorf("Monitors = %v, want nil %v", doc.Monitors, tt.wantNil)
+			}
+			if len(doc.Monitors) != tt.wantMonitors {
+				t.Errorf("len(Monitors) = %d, want %d", len(doc.Monitors), tt.wantMonitors)
+			}
+		})
+	}
+}
+
+// Test_StripRuntime tests runtime fields are dropped from import rows
+func Test_StripRuntime(t *testing.T) {
+	got, err := StripRuntime(json.RawMessage(`{"address": "https://a.example.com", "status": "down", "state": {"consecutive_failures": 3}, "paused": true, "last_ping_at": null}`))
+	if err != nil {
+		t.Fatalf("StripRuntime() error = %v", err)
+	}
+	if want := `{"address":"https://a.example.com"}`; string(got) != want {
+		t.Errorf("StripRuntime() = %s, want %s", got, want)
+	}
+
+	if _, err := StripRuntime(json.RawMessage(`["https://a.example.com"]`)); err == nil {
+		t.Error("StripRuntime(array) error = nil, want an error")
+	}
+}
@@ -0,0 +1,23 @@
+package bulk
+
+// MergePatch applies a JSON merge patch (RFC 7396) to doc, in place, and
+// returns it: a null member removes the key, an object merges into the object
+// it replaces member by member, and any other value, arrays included, replaces
+// the old one whole.
+func MergePatch(doc, patch map[string]any) map[string]any {
+	if doc == nil {
+		doc = make(map[string]any)
+	}
+	for key, value := range patch {
+		switch value := value.(type) {
+		case nil:
+			delete(doc, key)
+		case map[string]any:
+			target, _ := doc[key].(map[string]any)
+			doc[key] = MergePatch(target, value)
+		default:
+			doc[key] = value
+		}
+	}
+	return doc
+}
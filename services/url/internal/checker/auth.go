@@ -0,0 +1,149 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+	"github.com/samims/hcaas/services/url/internal/service"
+)
+
+const (
+	// tokenRefreshMargin renews OAuth2 tokens this long before they expire
+	tokenRefreshMargin = 30 * time.Second
+	// defaultTokenTTL applies when the token endpoint doesn't say when a token expires
+	defaultTokenTTL = 5 * time.Minute
+	// maxTokenResponseBytes caps how much of a token response is read
+	maxTokenResponseBytes = 64 << 10
+)
+
+// authenticator applies stored credentials to check requests and caches the
+// OAuth2 tokens it fetches for them
+type authenticator struct {
+	creds  service.CredentialService
+	client *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]cachedToken // by credential ID
+}
+
+type cachedToken struct {
+	value   string
+	expires time.Time
+	version time.Time // the credential's UpdatedAt, so edits aren't masked by the cache
+}
+
+func newAuthenticator(creds service.CredentialService, client *http.Client) *authenticator {
+	return &authenticator{creds: creds, client: client, tokens: make(map[string]cachedToken)}
+}
+
+// apply adds the credential's authentication to req
+func (a *authenticator) apply(ctx context.Context, req *http.Request, credentialID string) error {
+	if a == nil || a.creds == nil {
+		return errors.New("credentials are not available to this checker")
+	}
+	c, err := a.creds.GetForCheck(ctx, credentialID)
+	if err != nil {
+		return err
+	}
+
+	switch c.Type {
+	case model.CredentialBasic:
+		req.SetBasicAuth(c.Username, c.Secret)
+	case model.CredentialBearer:
+		req.Header.Set("Authorization", "Bearer "+c.Secret)
+	case model.CredentialAPIKey:
+		req.Header.Set(c.Header, c.Secret)
+	case model.CredentialOAuth2:
+		token, err := a.token(ctx, c)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case model.CredentialClientCert:
+		return errors.New("client_cert credentials are presented through transport.client_cert_id")
+	default:
+		return fmt.Errorf("unsupported credential type %q", c.Type)
+	}
+	return nil
+}
+
+// invalidate drops the cached token of a credential the server rejected, so
+// the next check fetches a new one
+func (a *authenticator) invalidate(credentialID string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.tokens, credentialID)
+}
+
+// token returns a cached access token for the credential, fetching one when
+// there is none or it is about to expire
+func (a *authenticator) token(ctx context.Context, c *model.Credential) (string, error) {
+	now := time.Now()
+	a.mu.Lock()
+	cached, ok := a.tokens[c.ID]
+	a.mu.Unlock()
+	if ok && cached.version.Equal(c.UpdatedAt) && now.Add(tokenRefreshMargin).Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	value, ttl, err := fetchToken(ctx, a.client, c)
+	if err != nil {
+		return "", err
+	}
+	a.mu.Lock()
+	a.tokens[c.ID] = cachedToken{value: value, expires: now.Add(ttl), version: c.UpdatedAt}
+	a.mu.Unlock()
+	return value, nil
+}
+
+// fetchToken runs the OAuth2 client-credentials grant (RFC 6749 section 4.4)
+func fetchToken(ctx context.Context, client *http.Client, c *model.Credential) (string, time.Duration, error) {
+	form := neturl.Values{"grant_type": {"client_credentials"}}
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(neturl.QueryEscape(c.ClientID), neturl.QueryEscape(c.Secret))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxTokenResponseBytes)).Decode(&body); err != nil {
+		return "", 0, fmt.Errorf("invalid token response: %w", err)
+	}
+	if body.AccessToken == "" {
+		return "", 0, errors.New("token response has no access_token")
+	}
+	ttl := defaultTokenTTL
+	if body.ExpiresIn > 0 {
+		ttl = time.Duration(body.ExpiresIn) * time.Second
+	}
+	return body.AccessToken, ttl, nil
+}
@@ -0,0 +1,107 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+	"github.com/samims/hcaas/services/url/internal/service"
+)
+
+// memCredentials serves decrypted credentials from memory
+type memCredentials struct {
+	service.CredentialService
+	byID map[string]model.Credential
+}
+
+func (m *memCredentials) GetForCheck(_ context.Context, id string) (*model.Credential, error) {
+	c, ok := m.byID[id]
+	if !ok {
+		return nil, appErr.NewNotFound("credential %s not found", id)
+	}
+	return &c, nil
+}
+
+// Test_pingHTTP_credentials checks each credential type reaches the server and
+// OAuth2 tokens are cached until the server rejects them.
+// Table Driven Test Pattern used
+func Test_pingHTTP_credentials(t *testing.T) {
+	var (
+		issued  atomic.Int32
+		revoked atomic.Bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			id, secret, _ := r.BasicAuth()
+			if r.FormValue("grant_type") != "client_credentials" || id != "probe" || secret != "s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			n := issued.Add(1)
+			revoked.Store(false)
+			fmt.Fprintf(w, `{"access_token":"tok-%d","token_type":"Bearer","expires_in":3600}`, n)
+		case "/basic":
+			if user, pass, _ := r.BasicAuth(); user != "admin" || pass != "pw" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		case "/key":
+			if r.Header.Get("X-Api-Token") != "k1" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		case "/oauth":
+			if revoked.Load() || r.Header.Get("Authorization") != fmt.Sprintf("Bearer tok-%d", issued.Load()) {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}
+	}))
+	defer server.Close()
+
+	creds := &memCredentials{byID: map[string]model.Credential{
+		"basic": {ID: "basic", Type: model.CredentialBasic, Username: "admin", Secret: "pw"},
+		"key":   {ID: "key", Type: model.CredentialAPIKey, Header: "X-Api-Token", Secret: "k1"},
+		"oauth": {ID: "oauth", Type: model.CredentialOAuth2, TokenURL: server.URL + "/token", ClientID: "probe", Secret: "s3cret"},
+		"bad":   {ID: "bad", Type: model.CredentialOAuth2, TokenURL: server.URL + "/token", ClientID: "probe", Secret: "wrong"},
+	}}
+	uc := &URLChecker{logger: slog.Default(), httpClient: server.Client(), auth: newAuthenticator(creds, server.Client())}
+
+	tests := []struct {
+		name       string
+		path       string
+		credential string
+		revoke     bool
+		want       string
+		wantClass  string
+		wantIssued int32
+	}{
+		{name: "basic", path: "/basic", credential: "basic", want: Healthy},
+		{name: "api key header", path: "/key", credential: "key", want: Healthy},
+		{name: "oauth2 fetches a token", path: "/oauth", credential: "oauth", want: Healthy, wantIssued: 1},
+		{name: "oauth2 reuses the cached token", path: "/oauth", credential: "oauth", want: Healthy, wantIssued: 1},
+		{name: "revoked token fails once", path: "/oauth", credential: "oauth", revoke: true, want: UnHealthy, wantClass: model.ErrorClassAssertion, wantIssued: 1},
+		{name: "then a new token is fetched", path: "/oauth", credential: "oauth", want: Healthy, wantIssued: 2},
+		{name: "rejected client", path: "/oauth", credential: "bad", want: UnHealthy, wantClass: model.ErrorClassAuth, wantIssued: 2},
+		{name: "missing credential", path: "/basic", credential: "gone", want: UnHealthy, wantClass: model.ErrorClassAuth, wantIssued: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked.Store(tt.revoke)
+			got := uc.pingHTTP(context.Background(), model.URL{Address: server.URL + tt.path, CredentialID: tt.credential})
+			if got.Status != tt.want {
+				t.Errorf("pingHTTP() status = %v, want %v (%s)", got.Status, tt.want, failureReason(got))
+			}
+			if got.ErrorClass != tt.wantClass {
+				t.Errorf("pingHTTP() error class = %q, want %q", got.ErrorClass, tt.wantClass)
+			}
+			if tt.wantIssued != 0 && issued.Load() != tt.wantIssued {
+				t.Errorf("tokens issued = %d, want %d", issued.Load(), tt.wantIssued)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package checker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// describeCert summarises the leaf certificate of a chain
+func describeCert(leaf *x509.Certificate) *model.CertInfo {
+	info := &model.CertInfo{
+		Subject:   leaf.Subject.String(),
+		Issuer:    leaf.Issuer.String(),
+		SANs:      slices.Clone(leaf.DNSNames),
+		NotBefore: leaf.NotBefore,
+		NotAfter:  leaf.NotAfter,
+	}
+	for _, ip := range leaf.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	return info
+}
+
+// verifyChain checks the presented chain against roots (the system pool when nil)
+// and the expected host name
+func verifyChain(chain []*x509.Certificate, host string, roots *x509.CertPool, now time.Time) *model.CertInfo {
+	if len(chain) == 0 {
+		return nil
+	}
+	info := describeCert(chain[0])
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	info.Valid = err == nil
+	if err != nil {
+		info.Error = err.Error()
+	}
+	return info
+}
+
+// certFromResponse describes the certificate of an established TLS connection.
+// A handshake only completes without verified chains when verification was
+// turned off, as with transport.insecure_skip_verify.
+func certFromResponse(state *tls.ConnectionState) *model.CertInfo {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	info := describeCert(state.PeerCertificates[0])
+	info.Valid = len(state.VerifiedChains) > 0
+	if !info.Valid {
+		info.Error = "not verified: certificate verification is turned off"
+	}
+	return info
+}
+
+// certFromError recovers the rejected certificate from a failed TLS handshake
+func certFromError(err error) *model.CertInfo {
+	var (
+		verifyErr *tls.CertificateVerificationError
+		hostErr   x509.HostnameError
+	)
+	var info *model.CertInfo
+	switch {
+	case errors.As(err, &verifyErr) && len(verifyErr.UnverifiedCertificates) > 0:
+		info = describeCert(verifyErr.UnverifiedCertificates[0])
+		info.Error = verifyErr.Err.Error()
+	case errors.As(err, &hostErr) && hostErr.Certificate != nil:
+		info = describeCert(hostErr.Certificate)
+		info.Error = hostErr.Error()
+	}
+	return info
+}
+
+// certNotifications announces certificates nearing expiry at each configured
+// threshold, and certificates that fail verification. It records what was sent
+// in state so every threshold and every invalid spell is announced only once.
+func certNotifications(url model.URL, status string, state *model.CheckState, cert *model.CertInfo, thresholds []int, now time.Time) []model.Notification {
+	// Nothing new was learned about the certificate, e.g. the host was unreachable
+	if cert == nil {
+		return nil
+	}
+
+	var notifications []model.Notification
+	notify := func(notificationType, message string) {
+		notifications = append(notifications, model.Notification{
+			UrlID:     url.ID,
+			Type:      notificationType,
+			Message:   message,
+			Status:    status,
+			CreatedAt: now,
+		})
+	}
+
+	// A monitor that skips verification accepts any certificate, so only expiry is announced
+	skipVerify := url.Transport != nil && url.Transport.InsecureSkipVerify
+	if cert.Valid || skipVerify {
+		state.CertInvalid = false
+	} else if !state.CertInvalid {
+		state.CertInvalid = true
+		notify(model.NotificationCertInvalid, fmt.Sprintf("Certificate for %s is invalid: %s", url.Address, cert.Error))
+	}
+
+	remaining := cert.NotAfter.Sub(now)
+	crossed := 0
+	for _, days := range thresholds {
+		if remaining <= time.Duration(days)*24*time.Hour && (crossed == 0 || days < crossed) {
+			crossed = days
+		}
+	}
+	switch {
+	case crossed == 0:
+		// Renewed, or not close to expiry yet
+		state.CertExpiryNotified = 0
+	case remaining <= 0:
+		// Expired certificates fail verification and are reported as invalid
+		state.CertExpiryNotified = crossed
+	case state.CertExpiryNotified == 0 || crossed < state.CertExpiryNotified:
+		state.CertExpiryNotified = crossed
+		notify(model.NotificationCertExpiring, fmt.Sprintf("Certificate for %s expires in %d day(s), on %s",
+			url.Address, int(remaining.Hours()/24), cert.NotAfter.UTC().Format(time.DateOnly)))
+	}
+	return notifications
+}
@@ -2,160 +2,553 @@ package checker
 
 import (
 	"context"
+	"crypto/x509"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/http/httptrace"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/samims/hcaas/services/url/internal/assertion"
+	"github.com/samims/hcaas/services/url/internal/config"
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
 	"github.com/samims/hcaas/services/url/internal/kafka"
 	"github.com/samims/hcaas/services/url/internal/metrics"
 	"github.com/samims/hcaas/services/url/internal/model"
 	"github.com/samims/hcaas/services/url/internal/service"
+	"github.com/samims/hcaas/services/url/internal/storage"
 )
 
 const (
-	Healthy   = "healthy"
-	UnHealthy = "unhealthy"
+	Healthy   = model.StatusHealthy
+	UnHealthy = model.StatusUnhealthy
 )
 
+// maxBodyBytes caps how much of a response body is read for assertions
+const maxBodyBytes = 1 << 20
+
+// syncOverlap is how far behind the watermark each sync reads again. updated_at
+// is the writing transaction's start time, so a write that commits after a sync
+// can carry a time older than the watermark that sync advanced to; requests time
+// out long before a transaction could stay open this long.
+const syncOverlap = 2 * time.Minute
+
 type URLChecker struct {
 	svc                  service.URLService
 	logger               *slog.Logger
 	httpClient           *http.Client
-	interval             time.Duration
+	cfg                  config.CheckerConfig
 	notificationProducer kafka.NotificationProducer
+	states               *stateTracker
+	sched                *scheduler
+	shards               *shardCoordinator
+	auth                 *authenticator
+	transports           *transportCache // clients for monitors with transport settings
+	rootCAs              *x509.CertPool  // trust roots for tls monitors; the system pool when nil
+	syncedUntil          time.Time
+	synced               map[string]time.Time // UpdatedAt applied per URL, to skip rows read again in the overlap
 }
 
 func NewURLChecker(
 	svc service.URLService,
+	creds service.CredentialService,
 	logger *slog.Logger,
 	client *http.Client,
-	interval time.Duration,
+	cfg config.CheckerConfig,
 	producer kafka.NotificationProducer,
+	leases storage.LeaseStorage,
 ) *URLChecker {
 	if producer == nil {
 		// This panic indicates a serious configuration error that should be caught
 		panic("NewURLChecker: notificationProducer cannot be nil")
 	}
-	return &URLChecker{
+	uc := &URLChecker{
 		svc:                  svc,
 		logger:               logger,
 		httpClient:           client,
-		interval:             interval,
+		cfg:                  cfg,
 		notificationProducer: producer,
+		states:               newStateTracker(),
+		sched:                newScheduler(cfg.Jitter),
+		auth:                 newAuthenticator(creds, client),
+		transports:           newTransportCache(creds),
+	}
+	// Without lease storage this instance checks every URL
+	if leases != nil {
+		uc.shards = newShardCoordinator(leases, cfg, logger)
+		uc.shards.onLost = uc.forgetShard
 	}
+	return uc
+}
+
+// owns reports whether this instance is responsible for checking the URL
+func (uc *URLChecker) owns(id string) bool {
+	return uc.shards == nil || uc.shards.owns(id)
 }
 
+// forgetShard drops cached state for a shard another replica takes over, so it
+// is reloaded from storage if the shard comes back
+func (uc *URLChecker) forgetShard(shard int) {
+	uc.states.forgetIf(func(id string) bool {
+		return shardOf(id, uc.cfg.Shards) == shard
+	})
+}
+
+// Start runs the scheduler until ctx is cancelled: monitors are kept in a min-heap
+// by next due time, handed to a fixed pool of workers when due, and additions or
+// changes are pulled from storage every SyncInterval.
 func (uc *URLChecker) Start(ctx context.Context) {
-	uc.logger.Info("URLChecker started")
+	uc.logger.Info("URLChecker started",
+		slog.Int("workers", uc.cfg.Workers),
+		slog.Duration("sync_interval", uc.cfg.SyncInterval))
 
-	ticker := time.NewTicker(uc.interval)
-	defer ticker.Stop()
+	uc.syncURLs(ctx)
+
+	if uc.shards != nil {
+		uc.shards.heartbeat(ctx)
+		go uc.shards.run(ctx)
+	}
+
+	jobs := make(chan model.URL)
+	var wg sync.WaitGroup
+	for i := 0; i < uc.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for url := range jobs {
+				metrics.CheckerBusyWorkers.Inc()
+				checkedAt := uc.checkURL(ctx, url)
+				metrics.CheckerBusyWorkers.Dec()
+				uc.sched.done(url.ID, checkedAt)
+			}
+		}()
+	}
+
+	go func() {
+		ticker := time.NewTicker(uc.cfg.SyncInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				uc.syncURLs(ctx)
+			}
+		}
+	}()
 
 	for {
+		url, due, ok := uc.sched.next(ctx)
+		if !ok {
+			break
+		}
+		// Every replica schedules every URL but only checks those in its shards,
+		// so taking over a shard needs no extra sync
+		if !uc.owns(url.ID) {
+			uc.sched.done(url.ID, due)
+			continue
+		}
 		select {
+		case jobs <- url:
+			metrics.CheckerScheduleLag.Observe(time.Since(due).Seconds())
 		case <-ctx.Done():
-			uc.logger.Info("URLChecker stopped")
-			return
-		case <-ticker.C:
-			uc.CheckAllURLs(ctx)
 		}
 	}
+
+	close(jobs)
+	wg.Wait()
+	uc.logger.Info("URLChecker stopped")
 }
 
-func (uc *URLChecker) CheckAllURLs(ctx context.Context) {
-	urls, err := uc.svc.GetAll(ctx)
+// syncURLs pulls URLs added or changed since the last sync into the scheduler,
+// and drops the ones that were paused or deleted. Each sync reads syncOverlap
+// behind the watermark again; versions already applied are skipped.
+func (uc *URLChecker) syncURLs(ctx context.Context) {
+	since := uc.syncedUntil
+	if !since.IsZero() {
+		since = since.Add(-syncOverlap)
+	}
+	urls, err := uc.svc.GetChangedSince(ctx, since)
 	if err != nil {
-		uc.logger.Error("Failed to fetch URLs", slog.Any("error", err))
+		uc.logger.Error("Failed to sync URLs", slog.Any("error", err))
 		return
 	}
+	if uc.synced == nil {
+		uc.synced = make(map[string]time.Time)
+	}
 
-	var wg sync.WaitGroup
-	sem := make(chan struct{}, 10) // Limit to 10 concurrent checks
-
+	changed := 0
 	for _, url := range urls {
-		wg.Add(1)
-		go func(url model.URL) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			uc.logger.Info("Checking URL", slog.String("id", url.ID), slog.String("address", url.Address))
-
-			status := uc.ping(ctx, url.Address)
-			uc.logger.Info("After ping", slog.String("url_id", url.ID), slog.Any("address", url.Address), slog.String("status", status))
-
-			err := uc.svc.UpdateStatus(ctx, url.ID, status)
-			if err != nil {
-				uc.logger.Error("Failed to update URL status",
-					slog.String("urlID", url.ID),
-					slog.String("status", status),
-					slog.Any("error", err),
-				)
-			} else {
-				uc.logger.Info("URL status updated",
-					slog.String("urlID", url.ID),
-					slog.String("address", url.Address),
-					slog.String("status", status),
-				)
-
-				if status == UnHealthy {
-					notification := model.Notification{
-						UrlID:     url.ID,
-						Type:      "url_unhealthy",
-						Message:   "URL is unhealthy: " + url.Address,
-						Status:    "pending",
-						CreatedAt: time.Now(),
-					}
-
-					if err := uc.notificationProducer.Publish(ctx, notification); err != nil {
-						uc.logger.Error("Failed to publish notification",
-							slog.String("url_id", url.ID),
-							slog.Any("error", err))
-					}
-				}
+		if applied, ok := uc.synced[url.ID]; ok && applied.Equal(url.UpdatedAt) {
+			continue
+		}
+		uc.synced[url.ID] = url.UpdatedAt
+		changed++
+
+		switch {
+		case url.Paused || url.DeletedAt != nil:
+			uc.sched.remove(url.ID)
+			uc.states.forget(url.ID)
+		default:
+			// The baseline is dropped when the address changes or content checks are turned off
+			if url.ContentBaseline == nil {
+				uc.states.resetContent(url.ID)
 			}
-		}(url)
+			uc.sched.upsert(url)
+		}
+		if url.UpdatedAt.After(uc.syncedUntil) {
+			uc.syncedUntil = url.UpdatedAt
+		}
 	}
+	// Versions older than the next overlap are never read again
+	for id, applied := range uc.synced {
+		if applied.Before(uc.syncedUntil.Add(-syncOverlap)) {
+			delete(uc.synced, id)
+		}
+	}
+	metrics.CheckerScheduledMonitors.Set(float64(uc.sched.len()))
 
-	wg.Wait()
+	if changed > 0 {
+		uc.logger.Info("URLs synced", slog.Int("changed", changed), slog.Int("scheduled", uc.sched.len()))
+	}
 }
 
-// ping performs a HTTP GET with timeout and metrics
-func (uc *URLChecker) ping(parentCtx context.Context, target string) string {
-	ctx, cancel := context.WithTimeout(parentCtx, 10*time.Second)
+// checkURL probes a single URL, records the result and announces status transitions.
+// It returns when the check ran.
+func (uc *URLChecker) checkURL(ctx context.Context, url model.URL) time.Time {
+	uc.logger.Info("Checking URL", slog.String("id", url.ID), slog.String("address", url.Address))
+
+	if url.Type == model.MonitorHeartbeat {
+		return uc.checkHeartbeat(ctx, url)
+	}
+
+	// Another replica may have checked this URL since it was synced
+	if uc.shards != nil && !uc.states.tracked(url.ID) {
+		fresh, ok := uc.reload(ctx, url)
+		if !ok {
+			return time.Now()
+		}
+		url = fresh
+	}
+
+	result := uc.ping(ctx, url)
+	result.URLID = url.ID
+	result.CheckedAt = time.Now()
+	uc.logger.Info("After ping", slog.String("url_id", url.ID), slog.Any("address", url.Address), slog.String("result", result.Status))
+
+	uc.record(ctx, url, uc.states.get(url), result)
+	return result.CheckedAt
+}
+
+// reload fetches the URL's latest state from storage. It returns false when the
+// URL was deleted or paused and has been unscheduled; on other errors the given
+// copy is kept.
+func (uc *URLChecker) reload(ctx context.Context, url model.URL) (model.URL, bool) {
+	fresh, err := uc.svc.GetForCheck(ctx, url.ID)
+	switch {
+	case appErr.IsNotFound(err):
+		uc.logger.Info("URL deleted, unscheduling", slog.String("urlID", url.ID))
+		uc.sched.remove(url.ID)
+		uc.states.forget(url.ID)
+		return url, false
+	case err != nil:
+		uc.logger.Warn("Failed to reload URL, using synced copy", slog.String("urlID", url.ID), slog.Any("error", err))
+		return url, true
+	case fresh.Paused:
+		uc.logger.Info("URL paused, unscheduling", slog.String("urlID", url.ID))
+		uc.sched.remove(url.ID)
+		uc.states.forget(url.ID)
+		return url, false
+	}
+	return *fresh, true
+}
+
+// record applies a check result to the URL's state, persists it and publishes
+// the notifications it triggers
+func (uc *URLChecker) record(ctx context.Context, url model.URL, prev monitorState, result model.CheckResult) {
+	next, notifications, err := applyResult(ctx, uc.svc, url, prev, &result, uc.cfg.CertExpiryDays)
+	if err != nil {
+		if appErr.IsNotFound(err) {
+			uc.logger.Info("URL deleted, unscheduling", slog.String("urlID", url.ID))
+			uc.sched.remove(url.ID)
+			uc.states.forget(url.ID)
+			return
+		}
+		if appErr.IsConflict(err) {
+			uc.logger.Info("URL changed while checking, result dropped", slog.String("urlID", url.ID))
+			return
+		}
+		uc.logger.Error("Failed to record check",
+			slog.String("urlID", url.ID),
+			slog.String("status", next.status),
+			slog.Any("error", err),
+		)
+		return
+	}
+	uc.states.set(url.ID, next)
+	uc.logger.Info("URL status updated",
+		slog.String("urlID", url.ID),
+		slog.String("address", url.Address),
+		slog.String("status", next.status),
+	)
+
+	for _, notification := range notifications {
+		uc.publish(ctx, notification)
+	}
+}
+
+// applyResult folds a check result into the previous state, persists both and
+// returns the new state with the notifications it triggers. On error the
+// returned state is the one that failed to persist.
+func applyResult(
+	ctx context.Context,
+	svc service.URLService,
+	url model.URL,
+	prev monitorState,
+	result *model.CheckResult,
+	certExpiryDays []int,
+) (monitorState, []model.Notification, error) {
+	next := nextState(url, prev, *result)
+	certAlerts := certNotifications(url, next.status, &next.counters, result.Cert, certExpiryDays, result.CheckedAt)
+	contentAlerts, err := contentNotifications(ctx, svc, url, next.status, &next.counters, *result)
+	if err != nil {
+		return next, nil, err
+	}
+
+	if url.Type == model.MonitorHeartbeat {
+		// Pings and missed-heartbeat checks race; the write fails with a conflict
+		// if the other landed since url was loaded
+		err = svc.RecordHeartbeat(ctx, result, next.status, next.counters, url)
+	} else {
+		err = svc.RecordCheck(ctx, result, next.status, next.counters)
+	}
+	if err != nil {
+		return next, nil, err
+	}
+
+	var notifications []model.Notification
+	if notification, ok := transitionNotification(url, prev, next, *result); ok {
+		notifications = append(notifications, notification)
+	}
+	notifications = append(notifications, certAlerts...)
+	notifications = append(notifications, contentAlerts...)
+	for i := range notifications {
+		notifications[i].Name = url.Name
+		notifications[i].Group = url.Group
+		notifications[i].Labels = url.Labels
+	}
+	return next, notifications, nil
+}
+
+func publish(ctx context.Context, producer kafka.NotificationProducer, logger *slog.Logger, notification model.Notification) {
+	if err := producer.Publish(ctx, notification); err != nil {
+		logger.Error("Failed to publish notification",
+			slog.String("url_id", notification.UrlID),
+			slog.String("type", notification.Type),
+			slog.Any("error", err))
+	}
+}
+
+func (uc *URLChecker) publish(ctx context.Context, notification model.Notification) {
+	publish(ctx, uc.notificationProducer, uc.logger, notification)
+}
+
+// ping probes the URL according to its monitor type, retrying as its policy
+// allows, and records metrics
+func (uc *URLChecker) ping(ctx context.Context, url model.URL) model.CheckResult {
+	result := uc.pingWithRetry(ctx, url)
+
+	status := model.StatusUP
+	if result.Status != Healthy {
+		status = model.StatusDown
+	}
+	metrics.URLCheckStatus.WithLabelValues(status).Inc()
+	// Checks that never reached the network have no latency to report
+	if result.Latency > 0 {
+		metrics.URLCheckDuration.WithLabelValues(status).Observe(result.Latency.Std().Seconds())
+	}
+	metrics.URLCheckOutcomes.WithLabelValues(checkOutcome(result)).Inc()
+	observeTimings(result.Timings)
+	for _, step := range result.Steps {
+		observeTimings(step.Timings)
+	}
+	return result
+}
+
+// pingOnce makes a single attempt at the check for the URL's monitor type
+func (uc *URLChecker) pingOnce(ctx context.Context, url model.URL) model.CheckResult {
+	switch url.Type {
+	case model.MonitorTCP:
+		return uc.pingTCP(ctx, url)
+	case model.MonitorDNS:
+		return uc.pingDNS(ctx, url)
+	case model.MonitorTLS:
+		return uc.pingTLS(ctx, url)
+	case model.MonitorGRPC:
+		return uc.pingGRPC(ctx, url)
+	case model.MonitorTransaction:
+		return uc.pingTransaction(ctx, url)
+	}
+	return uc.pingHTTP(ctx, url)
+}
+
+// checkTimeout is the URL's timeout, or the default when unset
+func checkTimeout(url model.URL) time.Duration {
+	if timeout := url.Timeout.Std(); timeout > 0 {
+		return timeout
+	}
+	return model.DefaultCheckTimeout.Std()
+}
+
+// pingHTTP performs the configured HTTP request with the URL's timeout and
+// evaluates its assertions
+func (uc *URLChecker) pingHTTP(parentCtx context.Context, url model.URL) model.CheckResult {
+	target := url.Address
+
+	assertions, err := assertion.Compile(url.Assertions)
+	if err != nil {
+		uc.logger.Warn("Invalid assertions", slog.String("address", target), slog.Any("error", err))
+		return model.CheckResult{Status: UnHealthy, Error: err.Error(), ErrorClass: model.ErrorClassRequest}
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, checkTimeout(url))
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	req, err := newCheckRequest(ctx, url.Method, target, url.Headers, url.Body)
 	if err != nil {
 		uc.logger.Warn("Failed to create HTTP request", slog.String("address", target), slog.Any("error", err))
-		metrics.URLCheckStatus.WithLabelValues(model.StatusDown).Inc()
-		return UnHealthy
+		return model.CheckResult{Status: UnHealthy, Error: err.Error(), ErrorClass: model.ErrorClassRequest}
+	}
+	if url.CredentialID != "" {
+		if err := uc.auth.apply(ctx, req, url.CredentialID); err != nil {
+			uc.logger.Warn("Failed to apply credential", slog.String("address", target), slog.Any("error", err))
+			return model.CheckResult{Status: UnHealthy, Error: err.Error(), ErrorClass: model.ErrorClassAuth}
+		}
 	}
 
-	start := time.Now()
-	resp, err := uc.httpClient.Do(req)
-	duration := time.Since(start).Seconds()
+	client, err := uc.clientFor(ctx, url)
+	if err != nil {
+		uc.logger.Warn("Failed to set up transport", slog.String("address", target), slog.Any("error", err))
+		return model.CheckResult{Status: UnHealthy, Error: err.Error(), ErrorClass: model.ErrorClassRequest}
+	}
 
+	resp, err := sendHTTP(client, req, assertion.NeedsBody(url.Assertions) || url.Content != nil)
 	if err != nil {
 		uc.logger.Warn("HTTP request failed", slog.String("address", target), slog.Any("error", err))
-		metrics.URLCheckStatus.WithLabelValues(model.StatusDown).Inc()
-		metrics.URLCheckDuration.WithLabelValues(model.StatusDown).Observe(duration)
-		return UnHealthy
+		return model.CheckResult{
+			Status:     UnHealthy,
+			StatusCode: resp.StatusCode,
+			Latency:    model.Duration(resp.Duration),
+			Error:      err.Error(),
+			ErrorClass: classifyError(err),
+			Cert:       resp.cert,
+			Timings:    resp.timings,
+		}
+	}
+
+	// A cached OAuth2 token may have been revoked early
+	if resp.StatusCode == http.StatusUnauthorized && url.CredentialID != "" {
+		uc.auth.invalidate(url.CredentialID)
+	}
+
+	result := model.CheckResult{
+		Status:     Healthy,
+		StatusCode: resp.StatusCode,
+		Latency:    model.Duration(resp.Duration),
+		Cert:       resp.cert,
+		Timings:    resp.timings,
+	}
+	result.FailedAssertions = assertions.Evaluate(resp.Response)
+	if url.Content != nil {
+		if result.Content, err = normalizeContent(resp.Body, url.Content.Ignore); err != nil {
+			uc.logger.Warn("Skipping content comparison", slog.String("address", target), slog.Any("error", err))
+		} else {
+			result.ContentHash = hashContent(result.Content)
+		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode >= http.StatusBadRequest {
-		uc.logger.Warn("Unhealthy HTTP status code",
+	if len(result.FailedAssertions) > 0 {
+		uc.logger.Warn("Assertions failed",
 			slog.String("address", target),
 			slog.Int("statusCode", resp.StatusCode),
+			slog.Any("failures", result.FailedAssertions),
 		)
-		metrics.URLCheckStatus.WithLabelValues(model.StatusDown).Inc()
-		metrics.URLCheckDuration.WithLabelValues(model.StatusDown).Observe(duration)
-		return UnHealthy
+		result.Status = UnHealthy
+		result.ErrorClass = model.ErrorClassAssertion
+	}
+	return result
+}
+
+// newCheckRequest builds a check request; an empty method means GET
+func newCheckRequest(ctx context.Context, method, target string, headers map[string]string, body string) (*http.Request, error) {
+	if method == "" {
+		method = model.DefaultCheckMethod
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, newBody(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	// net/http ignores Host in the header map, so honour it explicitly
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+	return req, nil
+}
+
+// httpResponse is what sendHTTP observed of a response
+type httpResponse struct {
+	assertion.Response
+	cert    *model.CertInfo
+	timings *model.HTTPTimings
+}
+
+// sendHTTP sends req and reads up to maxBodyBytes of the body, keeping it when
+// keepBody is set. The response carries whatever was received even when err is
+// set, and the certificate is the server's, from the handshake or the TLS error.
+func sendHTTP(client *http.Client, req *http.Request, keepBody bool) (httpResponse, error) {
+	timer := &phaseTimer{}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), timer.trace()))
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return httpResponse{Response: assertion.Response{Duration: time.Since(start)}, cert: certFromError(err)}, err
+	}
+	defer resp.Body.Close()
+
+	out := httpResponse{
+		Response: assertion.Response{StatusCode: resp.StatusCode, Header: resp.Header},
+		cert:     certFromResponse(resp.TLS),
+	}
+	if keepBody {
+		out.Body, err = io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	} else {
+		// Read it anyway so the transfer phase is measured
+		_, err = io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodyBytes))
+	}
+	end := time.Now()
+	out.Duration = end.Sub(start)
+	out.timings = timer.timings(end)
+	return out, err
+}
+
+// failureReason summarises why a result is unhealthy
+func failureReason(result model.CheckResult) string {
+	if result.Error != "" {
+		return result.Error
 	}
+	return strings.Join(result.FailedAssertions, "; ")
+}
 
-	metrics.URLCheckStatus.WithLabelValues(model.StatusUP).Inc()
-	metrics.URLCheckDuration.WithLabelValues(model.StatusUP).Observe(duration)
-	return Healthy
+// newBody returns nil for an empty body so GET/HEAD requests carry no payload
+func newBody(body string) io.Reader {
+	if body == "" {
+		return nil
+	}
+	return strings.NewReader(body)
 }
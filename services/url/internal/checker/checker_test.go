@@ -0,0 +1,257 @@
+package checker
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+	"github.com/samims/hcaas/services/url/internal/service"
+)
+
+// Test_URLChecker_ping verifies the per-URL check spec is honoured.
+// Table Driven Test Pattern used
+func Test_URLChecker_ping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/post-only":
+			body, _ := io.ReadAll(r.Body)
+			if r.Method != http.MethodPost || r.Header.Get("X-Token") != "secret" || string(body) != `{"ping":true}` {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	uc := &URLChecker{logger: slog.Default(), httpClient: server.Client()}
+
+	tests := []struct {
+		name      string
+		url       model.URL
+		want      string
+		wantClass string
+	}{
+		{
+			name: "default GET",
+			url:  model.URL{Address: server.URL + "/"},
+			want: Healthy,
+		},
+		{
+			name: "POST with headers and body",
+			url: model.URL{
+				Address: server.URL + "/post-only",
+				Method:  http.MethodPost,
+				Headers: map[string]string{"X-Token": "secret"},
+				Body:    `{"ping":true}`,
+			},
+			want: Healthy,
+		},
+		{
+			name:      "POST-only endpoint probed with GET",
+			url:       model.URL{Address: server.URL + "/post-only"},
+			want:      UnHealthy,
+			wantClass: model.ErrorClassAssertion,
+		},
+		{
+			name:      "per-URL timeout exceeded",
+			url:       model.URL{Address: server.URL + "/slow", Timeout: model.Duration(50 * time.Millisecond)},
+			want:      UnHealthy,
+			wantClass: model.ErrorClassTimeout,
+		},
+		{
+			name:      "connection refused",
+			url:       model.URL{Address: "http://127.0.0.1:1/"},
+			want:      UnHealthy,
+			wantClass: model.ErrorClassRefused,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := uc.ping(context.Background(), tt.url)
+			if got.Status != tt.want {
+				t.Errorf("ping() status = %v, want %v", got.Status, tt.want)
+			}
+			if got.ErrorClass != tt.wantClass {
+				t.Errorf("ping() error class = %q, want %q", got.ErrorClass, tt.wantClass)
+			}
+		})
+	}
+}
+
+// Test_nextState feeds a sequence of raw outcomes through the state machine and
+// checks the confirmed statuses and the notifications they trigger.
+func Test_nextState(t *testing.T) {
+	const (
+		H = Healthy
+		U = UnHealthy
+	)
+
+	tests := []struct {
+		name       string
+		url        model.URL
+		outcomes   []string
+		wantStatus []string
+		wantNotify []string
+	}{
+		{
+			name:       "defaults notify on every transition",
+			url:        model.URL{Status: model.StatusUnknown},
+			outcomes:   []string{U, U, H, H},
+			wantStatus: []string{U, U, H, H},
+			wantNotify: []string{model.NotificationURLDown, "", model.NotificationURLRecovered, ""},
+		},
+		{
+			name:       "first passing check is silent",
+			url:        model.URL{Status: model.StatusUnknown},
+			outcomes:   []string{H, H},
+			wantStatus: []string{H, H},
+			wantNotify: []string{"", ""},
+		},
+		{
+			name:       "blip below failure threshold is ignored",
+			url:        model.URL{Status: H, FailureThreshold: 3, RecoveryThreshold: 2},
+			outcomes:   []string{U, U, H, U, U, U, H, H},
+			wantStatus: []string{H, H, H, H, H, U, U, H},
+			wantNotify: []string{"", "", "", "", "", model.NotificationURLDown, "", model.NotificationURLRecovered},
+		},
+		{
+			name:       "flapping suppresses alerts until it settles",
+			url:        model.URL{Status: H, FlapThreshold: 3, FlapWindow: model.Duration(time.Hour)},
+			outcomes:   []string{U, H, U, H, U, U},
+			wantStatus: []string{U, H, model.StatusFlapping, model.StatusFlapping, model.StatusFlapping, model.StatusFlapping},
+			wantNotify: []string{model.NotificationURLDown, model.NotificationURLRecovered, model.NotificationURLFlapping, "", "", ""},
+		},
+		{
+			name:       "flapping ends once changes leave the window",
+			url:        model.URL{Status: H, FlapThreshold: 2, FlapWindow: model.Duration(3 * time.Minute)},
+			outcomes:   []string{U, H, H, H, H},
+			wantStatus: []string{U, model.StatusFlapping, model.StatusFlapping, model.StatusFlapping, H},
+			wantNotify: []string{model.NotificationURLDown, model.NotificationURLFlapping, "", "", model.NotificationURLRecovered},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+			st := monitorState{status: tt.url.Status}
+
+			for i, outcome := range tt.outcomes {
+				result := model.CheckResult{Status: outcome, CheckedAt: start.Add(time.Duration(i) * time.Minute)}
+				next := nextState(tt.url, st, result)
+				notification, _ := transitionNotification(tt.url, st, next, result)
+
+				if next.status != tt.wantStatus[i] {
+					t.Errorf("check %d: status = %q, want %q", i, next.status, tt.wantStatus[i])
+				}
+				if notification.Type != tt.wantNotify[i] {
+					t.Errorf("check %d: notification = %q, want %q", i, notification.Type, tt.wantNotify[i])
+				}
+				st = next
+			}
+		})
+	}
+}
+
+func Test_transitionNotification_downtime(t *testing.T) {
+	downSince := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	url := model.URL{ID: "u1", Address: "https://example.com"}
+	prev := monitorState{status: UnHealthy, changedAt: downSince}
+	next := monitorState{status: Healthy}
+	result := model.CheckResult{Status: Healthy, CheckedAt: downSince.Add(10 * time.Minute)}
+
+	got, ok := transitionNotification(url, prev, next, result)
+	if !ok || got.Type != model.NotificationURLRecovered {
+		t.Fatalf("transitionNotification() = %+v, want url_recovered", got)
+	}
+	if got.PreviousStatus != UnHealthy {
+		t.Errorf("PreviousStatus = %q, want %q", got.PreviousStatus, UnHealthy)
+	}
+	if got.DowntimeSeconds != 600 {
+		t.Errorf("DowntimeSeconds = %d, want 600", got.DowntimeSeconds)
+	}
+}
+
+// changedURLs serves a fixed batch of changed URLs to syncURLs
+type changedURLs struct {
+	service.URLService
+	urls []model.URL
+}
+
+func (c *changedURLs) GetChangedSince(context.Context, time.Time) ([]model.URL, error) {
+	return c.urls, nil
+}
+
+// Test_URLChecker_syncURLs checks paused and deleted monitors leave the schedule
+func Test_URLChecker_syncURLs(t *testing.T) {
+	now := time.Now()
+	svc := &changedURLs{urls: []model.URL{
+		{ID: "live", Interval: model.Duration(time.Minute), UpdatedAt: now},
+		{ID: "paused", Interval: model.Duration(time.Minute), UpdatedAt: now},
+		{ID: "deleted", Interval: model.Duration(time.Minute), UpdatedAt: now},
+	}}
+	uc := &URLChecker{svc: svc, logger: slog.Default(), states: newStateTracker(), sched: newScheduler(0)}
+	uc.syncURLs(context.Background())
+	if n := uc.sched.len(); n != 3 {
+		t.Fatalf("scheduled %d monitors, want 3", n)
+	}
+
+	svc.urls = []model.URL{
+		{ID: "paused", Interval: model.Duration(time.Minute), UpdatedAt: now.Add(time.Second), Paused: true},
+		{ID: "deleted", Interval: model.Duration(time.Minute), UpdatedAt: now.Add(time.Second), DeletedAt: &now},
+	}
+	uc.syncURLs(context.Background())
+	if n := uc.sched.len(); n != 1 {
+		t.Errorf("scheduled %d monitors after pause and delete, want 1", n)
+	}
+	if !uc.syncedUntil.Equal(now.Add(time.Second)) {
+		t.Errorf("syncedUntil = %v, want the newest UpdatedAt", uc.syncedUntil)
+	}
+}
+
+// sinceURLs records the watermark syncURLs asks for
+type sinceURLs struct {
+	changedURLs
+	since time.Time
+}
+
+func (s *sinceURLs) GetChangedSince(_ context.Context, since time.Time) ([]model.URL, error) {
+	s.since = since
+	return s.urls, nil
+}
+
+// Test_URLChecker_syncURLs_lateCommit checks a write that commits after a sync,
+// with an updated_at older than that sync's watermark, is still picked up
+func Test_URLChecker_syncURLs_lateCommit(t *testing.T) {
+	now := time.Now()
+	svc := &sinceURLs{changedURLs: changedURLs{urls: []model.URL{
+		{ID: "fast", Interval: model.Duration(time.Minute), UpdatedAt: now},
+	}}}
+	uc := &URLChecker{svc: svc, logger: slog.Default(), states: newStateTracker(), sched: newScheduler(0)}
+	uc.syncURLs(context.Background())
+
+	// "slow" started before "fast" but committed after the first sync
+	svc.urls = []model.URL{
+		{ID: "slow", Interval: model.Duration(time.Minute), UpdatedAt: now.Add(-time.Second)},
+		{ID: "fast", Interval: model.Duration(time.Minute), UpdatedAt: now},
+	}
+	uc.syncURLs(context.Background())
+	if !svc.since.Before(now.Add(-time.Second)) {
+		t.Errorf("second sync read from %v, want before the late write", svc.since)
+	}
+	if n := uc.sched.len(); n != 2 {
+		t.Errorf("scheduled %d monitors, want 2", n)
+	}
+	if !uc.syncedUntil.Equal(now) {
+		t.Errorf("syncedUntil = %v, want %v", uc.syncedUntil, now)
+	}
+}
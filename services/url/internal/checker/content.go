@@ -0,0 +1,121 @@
+package checker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/samims/hcaas/services/url/internal/diff"
+	"github.com/samims/hcaas/services/url/internal/model"
+	"github.com/samims/hcaas/services/url/internal/service"
+)
+
+// maxDiffBytes caps the diff sent with content_changed; the full diff is
+// available from GET /urls/{id}/content
+const maxDiffBytes = 64 << 10
+
+// normalizeContent removes what the monitor ignores from body so only
+// meaningful changes alter its hash
+func normalizeContent(body []byte, ignore []string) (string, error) {
+	content := strings.ReplaceAll(string(body), "\r\n", "\n")
+	for _, pattern := range ignore {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+		}
+		content = re.ReplaceAllString(content, "")
+	}
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")) + "\n", nil
+}
+
+func hashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// contentNotifications compares the content of a passing check with the
+// monitor's baseline. The first content seen becomes the baseline; after that
+// every new variant is stored for review and announced once, until it is
+// accepted or the content returns to the baseline.
+func contentNotifications(
+	ctx context.Context,
+	svc service.URLService,
+	url model.URL,
+	status string,
+	state *model.CheckState,
+	result model.CheckResult,
+) ([]model.Notification, error) {
+	// Error pages and unreachable hosts say nothing about the content
+	if url.Content == nil || result.Status != Healthy || result.ContentHash == "" {
+		return nil, nil
+	}
+
+	// A baseline accepted through the API replaces the one being compared to
+	if url.ContentBaseline != nil {
+		state.ContentBaseline = url.ContentBaseline.Hash
+	}
+	snapshot := model.ContentSnapshot{Hash: result.ContentHash, Body: result.Content, SeenAt: result.CheckedAt}
+
+	if state.ContentBaseline == "" {
+		if err := svc.InitContentBaseline(ctx, url.ID, snapshot); err != nil {
+			return nil, err
+		}
+		state.ContentBaseline = snapshot.Hash
+		return nil, nil
+	}
+	if snapshot.Hash == state.ContentBaseline {
+		state.ContentChanged = ""
+		return nil, nil
+	}
+	if snapshot.Hash == state.ContentChanged {
+		return nil, nil
+	}
+
+	// The synced copy may be missing the baseline body or a recent acceptance
+	fresh, err := svc.GetForCheck(ctx, url.ID)
+	if err != nil {
+		return nil, err
+	}
+	var baseline string
+	if fresh.ContentBaseline != nil {
+		state.ContentBaseline = fresh.ContentBaseline.Hash
+		if snapshot.Hash == state.ContentBaseline {
+			state.ContentChanged = ""
+			return nil, nil
+		}
+		baseline = fresh.ContentBaseline.Body
+	}
+
+	if err := svc.SaveContentChanged(ctx, url.ID, snapshot); err != nil {
+		return nil, err
+	}
+	state.ContentChanged = snapshot.Hash
+
+	return []model.Notification{{
+		UrlID:     url.ID,
+		Type:      model.NotificationContentChanged,
+		Message:   fmt.Sprintf("Content of %s changed", url.Address),
+		Status:    status,
+		Diff:      truncateDiff(diff.Unified("baseline", "changed", baseline, snapshot.Body)),
+		CreatedAt: result.CheckedAt,
+	}}, nil
+}
+
+// truncateDiff cuts d at a line boundary to fit maxDiffBytes
+func truncateDiff(d string) string {
+	if len(d) <= maxDiffBytes {
+		return d
+	}
+	cut := d[:maxDiffBytes]
+	if i := strings.LastIndexByte(cut, '\n'); i >= 0 {
+		cut = cut[:i+1]
+	}
+	return cut + "... diff truncated\n"
+}
@@ -0,0 +1,111 @@
+package checker
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+func (m *memURLService) InitContentBaseline(_ context.Context, _ string, snapshot model.ContentSnapshot) error {
+	if m.url.ContentBaseline == nil {
+		m.url.ContentBaseline = &snapshot
+	}
+	return nil
+}
+
+func (m *memURLService) SaveContentChanged(_ context.Context, _ string, snapshot model.ContentSnapshot) error {
+	m.url.ContentChanged = &snapshot
+	return nil
+}
+
+func Test_normalizeContent(t *testing.T) {
+	ignore := []string{`generated at \d\d:\d\d`, `nonce="[^"]*"`}
+
+	a, err := normalizeContent([]byte("<p>Hi</p>  \r\n<!-- generated at 10:00 -->\r\n<script nonce=\"abc\"></script>\r\n"), ignore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := normalizeContent([]byte("<p>Hi</p>\n<!-- generated at 23:59 -->\n<script nonce=\"xyz\"></script>\n\n"), ignore)
+	if a != b {
+		t.Errorf("normalizeContent() differs on ignored churn:\n%q\n%q", a, b)
+	}
+	c, _ := normalizeContent([]byte("<p>Bye</p>\n"), ignore)
+	if hashContent(a) == hashContent(c) {
+		t.Error("hashContent() is equal for different content")
+	}
+}
+
+// Test_content_flow drives a content monitor through a baseline, an unexpected
+// change and its acceptance
+func Test_content_flow(t *testing.T) {
+	body := "Welcome\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body + "rendered " + time.Now().Format(time.RFC3339Nano) + "\n"))
+	}))
+	defer server.Close()
+
+	svc := &memURLService{url: model.URL{
+		ID:      "u1",
+		Address: server.URL,
+		Status:  model.StatusUnknown,
+		Content: &model.ContentCheck{Ignore: []string{`rendered \S+`}},
+	}}
+	uc := &URLChecker{logger: slog.Default(), httpClient: server.Client()}
+
+	steps := []struct {
+		name     string
+		body     string
+		accept   bool
+		wantDiff []string // nil when no content_changed is expected
+	}{
+		{name: "first content becomes the baseline", body: "Welcome\n"},
+		{name: "ignored churn is not a change", body: "Welcome\n"},
+		{name: "change is announced with a diff", body: "Defaced\n", wantDiff: []string{"-Welcome", "+Defaced"}},
+		{name: "same change is announced once", body: "Defaced\n"},
+		{name: "accepted change is the new baseline", body: "Defaced\n", accept: true},
+		{name: "old content is now a change", body: "Welcome\n", wantDiff: []string{"-Defaced", "+Welcome"}},
+	}
+	st := stateFromURL(svc.url)
+	for _, step := range steps {
+		body = step.body
+		if step.accept {
+			svc.url.ContentBaseline, svc.url.ContentChanged = svc.url.ContentChanged, nil
+		}
+
+		result := uc.pingHTTP(context.Background(), svc.url)
+		result.URLID = svc.url.ID
+		result.CheckedAt = time.Now()
+		next, notifications, err := applyResult(context.Background(), svc, svc.url, st, &result, nil)
+		if err != nil {
+			t.Fatalf("%s: applyResult() error = %v", step.name, err)
+		}
+		st = next
+
+		var changed []model.Notification
+		for _, n := range notifications {
+			if n.Type == model.NotificationContentChanged {
+				changed = append(changed, n)
+			}
+		}
+		if step.wantDiff == nil {
+			if len(changed) != 0 {
+				t.Errorf("%s: got content_changed %q, want none", step.name, changed[0].Diff)
+			}
+			continue
+		}
+		if len(changed) != 1 {
+			t.Fatalf("%s: got %d content_changed notifications, want 1", step.name, len(changed))
+		}
+		for _, line := range step.wantDiff {
+			if !strings.Contains(changed[0].Diff, line+"\n") {
+				t.Errorf("%s: diff %q does not contain %q", step.name, changed[0].Diff, line)
+			}
+		}
+	}
+}
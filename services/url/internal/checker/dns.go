@@ -0,0 +1,160 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/assertion"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// pingDNS resolves the URL's name and compares the answer set with the expected one
+func (uc *URLChecker) pingDNS(parentCtx context.Context, url model.URL) model.CheckResult {
+	name := url.Address
+
+	assertions, err := assertion.Compile(url.Assertions)
+	if err != nil {
+		uc.logger.Warn("Invalid assertions", slog.String("address", name), slog.Any("error", err))
+		return model.CheckResult{Status: UnHealthy, Error: err.Error(), ErrorClass: model.ErrorClassRequest}
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, checkTimeout(url))
+	defer cancel()
+
+	spec := model.DNSCheck{RecordType: model.DNSRecordA}
+	if url.DNS != nil {
+		spec = *url.DNS
+	}
+
+	start := time.Now()
+	answers, err := lookupDNS(ctx, newResolver(spec.Resolver), spec.RecordType, name)
+	duration := time.Since(start)
+	if err != nil {
+		uc.logger.Warn("DNS lookup failed", slog.String("name", name), slog.String("type", spec.RecordType), slog.Any("error", err))
+		return model.CheckResult{
+			Status:     UnHealthy,
+			Latency:    model.Duration(duration),
+			Error:      err.Error(),
+			ErrorClass: classifyError(err),
+		}
+	}
+
+	result := model.CheckResult{Status: Healthy, Latency: model.Duration(duration)}
+	switch {
+	case len(spec.Expected) == 0 && len(answers) == 0:
+		result.FailedAssertions = []string{fmt.Sprintf("no %s records for %s", spec.RecordType, name)}
+	case len(spec.Expected) > 0 && !sameAnswers(answers, expectedAnswers(spec)):
+		result.FailedAssertions = []string{fmt.Sprintf("%s answers %v do not match expected %v", spec.RecordType, answers, spec.Expected)}
+	}
+	result.FailedAssertions = append(result.FailedAssertions, assertions.Evaluate(assertion.Response{Duration: duration})...)
+
+	if len(result.FailedAssertions) > 0 {
+		uc.logger.Warn("Assertions failed", slog.String("name", name), slog.Any("failures", result.FailedAssertions))
+		result.Status = UnHealthy
+		result.ErrorClass = model.ErrorClassAssertion
+	}
+	return result
+}
+
+// newResolver queries the given host:port instead of the system resolver when set
+func newResolver(server string) *net.Resolver {
+	if server == "" {
+		return net.DefaultResolver
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// lookupDNS returns the sorted answers for name, formatted the way expected
+// answers are normalised by the service
+func lookupDNS(ctx context.Context, r *net.Resolver, recordType, name string) ([]string, error) {
+	fqdn := name + "."
+	var answers []string
+
+	switch recordType {
+	case model.DNSRecordA, model.DNSRecordAAAA:
+		network := "ip4"
+		if recordType == model.DNSRecordAAAA {
+			network = "ip6"
+		}
+		ips, err := r.LookupNetIP(ctx, network, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			answers = append(answers, ip.Unmap().String())
+		}
+	case model.DNSRecordCNAME:
+		cname, err := r.LookupCNAME(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, normalizeName(cname))
+	case model.DNSRecordMX:
+		mxs, err := r.LookupMX(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			answers = append(answers, mxAnswer(uint64(mx.Pref), mx.Host))
+		}
+	case model.DNSRecordTXT:
+		txts, err := r.LookupTXT(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, txts...)
+	default:
+		return nil, fmt.Errorf("unsupported dns record type %q", recordType)
+	}
+
+	slices.Sort(answers)
+	return slices.Compact(answers), nil
+}
+
+// mxAnswer formats an MX record as "<preference> <host>"
+func mxAnswer(pref uint64, host string) string {
+	return strconv.FormatUint(pref, 10) + " " + normalizeName(host)
+}
+
+// expectedAnswers formats expected MX answers the way observed ones are, so
+// monitors saved before the service normalised them still compare by value
+func expectedAnswers(spec model.DNSCheck) []string {
+	if spec.RecordType != model.DNSRecordMX {
+		return spec.Expected
+	}
+	expected := make([]string, len(spec.Expected))
+	for i, value := range spec.Expected {
+		expected[i] = value
+		fields := strings.Fields(value)
+		if len(fields) != 2 {
+			continue
+		}
+		if pref, err := strconv.ParseUint(fields[0], 10, 16); err == nil {
+			expected[i] = mxAnswer(pref, fields[1])
+		}
+	}
+	return expected
+}
+
+// sameAnswers compares two answer sets ignoring order and duplicates
+func sameAnswers(got, want []string) bool {
+	want = slices.Clone(want)
+	slices.Sort(want)
+	return slices.Equal(got, slices.Compact(want))
+}
+
+func normalizeName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
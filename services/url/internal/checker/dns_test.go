@@ -0,0 +1,167 @@
+package checker
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// dnsZone is a tiny authoritative zone served over UDP for tests
+type dnsZone map[string][]dnsmessage.Resource
+
+// serveDNS answers queries from zone on a local UDP port and returns its address
+func serveDNS(t *testing.T, zone dnsZone) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { pc.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var p dnsmessage.Parser
+			header, err := p.Start(buf[:n])
+			if err != nil {
+				continue
+			}
+			q, err := p.Question()
+			if err != nil {
+				continue
+			}
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: header.ID, Response: true, Authoritative: true},
+				Questions: []dnsmessage.Question{q},
+			}
+			resp.Answers = zone.answer(q.Name.String(), q.Type)
+			if _, ok := zone[strings.ToLower(q.Name.String())]; !ok {
+				resp.Header.RCode = dnsmessage.RCodeNameError
+			}
+			out, err := resp.Pack()
+			if err != nil {
+				continue
+			}
+			pc.WriteTo(out, addr)
+		}
+	}()
+	return pc.LocalAddr().String()
+}
+
+// answer returns the records of the requested type, following a CNAME if there is one
+func (z dnsZone) answer(name string, qtype dnsmessage.Type) []dnsmessage.Resource {
+	var out []dnsmessage.Resource
+	for _, rr := range z[strings.ToLower(name)] {
+		switch {
+		case rr.Header.Type == qtype:
+			out = append(out, rr)
+		case rr.Header.Type == dnsmessage.TypeCNAME:
+			out = append(out, rr)
+			out = append(out, z.answer(rr.Body.(*dnsmessage.CNAMEResource).CNAME.String(), qtype)...)
+		}
+	}
+	return out
+}
+
+func rr(name string, body dnsmessage.ResourceBody) dnsmessage.Resource {
+	var typ dnsmessage.Type
+	switch body.(type) {
+	case *dnsmessage.AResource:
+		typ = dnsmessage.TypeA
+	case *dnsmessage.AAAAResource:
+		typ = dnsmessage.TypeAAAA
+	case *dnsmessage.CNAMEResource:
+		typ = dnsmessage.TypeCNAME
+	case *dnsmessage.MXResource:
+		typ = dnsmessage.TypeMX
+	case *dnsmessage.TXTResource:
+		typ = dnsmessage.TypeTXT
+	}
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Type: typ, Class: dnsmessage.ClassINET, TTL: 60},
+		Body:   body,
+	}
+}
+
+func Test_URLChecker_pingDNS(t *testing.T) {
+	resolver := serveDNS(t, dnsZone{
+		"app.example.test.": {
+			rr("app.example.test.", &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}}),
+			rr("app.example.test.", &dnsmessage.AResource{A: [4]byte{10, 0, 0, 2}}),
+			rr("app.example.test.", &dnsmessage.AAAAResource{AAAA: [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}}),
+		},
+		"www.example.test.": {
+			rr("www.example.test.", &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("app.example.test.")}),
+		},
+		"example.test.": {
+			rr("example.test.", &dnsmessage.MXResource{Pref: 10, MX: dnsmessage.MustNewName("mail.example.test.")}),
+			rr("example.test.", &dnsmessage.TXTResource{TXT: []string{"v=spf1 -all"}}),
+		},
+	})
+
+	uc := &URLChecker{logger: slog.Default()}
+	dns := func(name, recordType string, expected ...string) model.URL {
+		return model.URL{Type: model.MonitorDNS, Address: name,
+			DNS: &model.DNSCheck{RecordType: recordType, Resolver: resolver, Expected: expected}}
+	}
+
+	tests := []struct {
+		name      string
+		url       model.URL
+		want      string
+		wantClass string
+	}{
+		{name: "A records in any order", url: dns("app.example.test", model.DNSRecordA, "10.0.0.2", "10.0.0.1"), want: Healthy},
+		{name: "AAAA record", url: dns("app.example.test", model.DNSRecordAAAA, "2001:db8::1"), want: Healthy},
+		{name: "CNAME target", url: dns("www.example.test", model.DNSRecordCNAME, "app.example.test"), want: Healthy},
+		{name: "MX record", url: dns("example.test", model.DNSRecordMX, "10 mail.example.test"), want: Healthy},
+		{name: "MX record saved unnormalised", url: dns("example.test", model.DNSRecordMX, "010  Mail.Example.Test."), want: Healthy},
+		{
+			name:      "MX record with another preference",
+			url:       dns("example.test", model.DNSRecordMX, "20 mail.example.test"),
+			want:      UnHealthy,
+			wantClass: model.ErrorClassAssertion,
+		},
+		{name: "TXT record", url: dns("example.test", model.DNSRecordTXT, "v=spf1 -all"), want: Healthy},
+		{name: "any answer without expectations", url: dns("app.example.test", model.DNSRecordA), want: Healthy},
+		{
+			name:      "record missing from answer set",
+			url:       dns("app.example.test", model.DNSRecordA, "10.0.0.1"),
+			want:      UnHealthy,
+			wantClass: model.ErrorClassAssertion,
+		},
+		{
+			name:      "changed record",
+			url:       dns("app.example.test", model.DNSRecordA, "10.0.0.1", "10.0.0.3"),
+			want:      UnHealthy,
+			wantClass: model.ErrorClassAssertion,
+		},
+		{
+			name:      "name does not exist",
+			url:       dns("gone.example.test", model.DNSRecordA),
+			want:      UnHealthy,
+			wantClass: model.ErrorClassDNS,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := uc.ping(context.Background(), tt.url)
+			if got.Status != tt.want {
+				t.Errorf("ping() status = %v, want %v (error %q, failures %v)", got.Status, tt.want, got.Error, got.FailedAssertions)
+			}
+			if got.ErrorClass != tt.wantClass {
+				t.Errorf("ping() error class = %q, want %q", got.ErrorClass, tt.wantClass)
+			}
+		})
+	}
+}
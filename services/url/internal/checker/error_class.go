@@ -0,0 +1,47 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// classifyError maps a transport error onto one of the model.ErrorClass values
+func classifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var (
+		dnsErr     *net.DNSError
+		certErr    *tls.CertificateVerificationError
+		hostErr    x509.HostnameError
+		authErr    x509.UnknownAuthorityError
+		invalidErr x509.CertificateInvalidError
+		recordErr  tls.RecordHeaderError
+		alertErr   tls.AlertError
+		netErr     net.Error
+		opErr      *net.OpError
+	)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return model.ErrorClassTimeout
+	case errors.As(err, &dnsErr):
+		return model.ErrorClassDNS
+	case errors.As(err, &certErr), errors.As(err, &hostErr), errors.As(err, &authErr),
+		errors.As(err, &invalidErr), errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return model.ErrorClassTLS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return model.ErrorClassRefused
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return model.ErrorClassTimeout
+	case errors.As(err, &opErr):
+		return model.ErrorClassConnection
+	}
+	return model.ErrorClassConnection
+}
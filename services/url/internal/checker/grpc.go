@@ -0,0 +1,121 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/samims/hcaas/services/url/internal/assertion"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// pingGRPC calls grpc.health.v1.Health/Check and maps the serving status onto a
+// check outcome: only SERVING is healthy
+func (uc *URLChecker) pingGRPC(parentCtx context.Context, url model.URL) model.CheckResult {
+	target := url.Address
+
+	assertions, err := assertion.Compile(url.Assertions)
+	if err != nil {
+		uc.logger.Warn("Invalid assertions", slog.String("address", target), slog.Any("error", err))
+		return model.CheckResult{Status: UnHealthy, Error: err.Error(), ErrorClass: model.ErrorClassRequest}
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, checkTimeout(url))
+	defer cancel()
+
+	var spec model.GRPCCheck
+	if url.GRPC != nil {
+		spec = *url.GRPC
+	}
+
+	creds := insecure.NewCredentials()
+	if spec.TLS {
+		serverName := spec.ServerName
+		if serverName == "" {
+			serverName, _, _ = net.SplitHostPort(target)
+		}
+		creds = credentials.NewTLS(&tls.Config{ServerName: serverName, RootCAs: uc.rootCAs})
+	}
+
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		uc.logger.Warn("Failed to create gRPC client", slog.String("address", target), slog.Any("error", err))
+		return model.CheckResult{Status: UnHealthy, Error: err.Error(), ErrorClass: model.ErrorClassRequest}
+	}
+	defer conn.Close()
+
+	var p peer.Peer
+	start := time.Now()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx,
+		&healthpb.HealthCheckRequest{Service: spec.Service},
+		grpc.Peer(&p),
+	)
+	duration := time.Since(start)
+
+	result := model.CheckResult{Status: Healthy, Latency: model.Duration(duration)}
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		result.Cert = certFromResponse(&info.State)
+	}
+
+	if err != nil {
+		uc.logger.Warn("gRPC health check failed", slog.String("address", target), slog.Any("error", err))
+		result.Status = UnHealthy
+		result.Error = err.Error()
+		result.ErrorClass = classifyGRPCError(err)
+		return result
+	}
+
+	if serving := resp.GetStatus(); serving != healthpb.HealthCheckResponse_SERVING {
+		result.FailedAssertions = append(result.FailedAssertions, fmt.Sprintf("health status is %s", serving))
+	}
+	result.FailedAssertions = append(result.FailedAssertions, assertions.Evaluate(assertion.Response{Duration: duration})...)
+
+	if len(result.FailedAssertions) > 0 {
+		uc.logger.Warn("Assertions failed", slog.String("address", target), slog.Any("failures", result.FailedAssertions))
+		result.Status = UnHealthy
+		result.ErrorClass = model.ErrorClassAssertion
+	}
+	return result
+}
+
+// classifyGRPCError maps RPC status codes onto error classes
+func classifyGRPCError(err error) string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return classifyError(err)
+	}
+	switch st.Code() {
+	case codes.DeadlineExceeded, codes.Canceled:
+		return model.ErrorClassTimeout
+	case codes.NotFound:
+		// The server doesn't know the requested service
+		return model.ErrorClassAssertion
+	case codes.Unimplemented:
+		// The server doesn't implement grpc.health.v1
+		return model.ErrorClassRequest
+	case codes.Unavailable:
+		// Transport errors only survive as text in the status message
+		msg := st.Message()
+		switch {
+		case strings.Contains(msg, "connection refused"):
+			return model.ErrorClassRefused
+		case strings.Contains(msg, "tls:"), strings.Contains(msg, "x509:"):
+			return model.ErrorClassTLS
+		case strings.Contains(msg, "no such host"):
+			return model.ErrorClassDNS
+		}
+	}
+	return model.ErrorClassConnection
+}
@@ -0,0 +1,104 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"log/slog"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// serveHealth starts a grpc.health.v1 server and returns its address
+func serveHealth(t *testing.T, opts ...grpc.ServerOption) (string, *health.Server) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := grpc.NewServer(opts...)
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+	return ln.Addr().String(), hs
+}
+
+func Test_URLChecker_pingGRPC(t *testing.T) {
+	addr, hs := serveHealth(t)
+	hs.SetServingStatus("orders.v1.Orders", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("billing.v1.Billing", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	// Reuse the httptest certificate (issued for 127.0.0.1 and example.com) for a TLS server
+	certSrv := httptest.NewUnstartedServer(nil)
+	certSrv.StartTLS()
+	defer certSrv.Close()
+	tlsAddr, _ := serveHealth(t, grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: certSrv.TLS.Certificates})))
+	roots := x509.NewCertPool()
+	roots.AddCert(certSrv.Certificate())
+
+	uc := &URLChecker{logger: slog.Default(), rootCAs: roots}
+	grpcURL := func(address string, spec *model.GRPCCheck) model.URL {
+		return model.URL{Type: model.MonitorGRPC, Address: address, GRPC: spec}
+	}
+
+	tests := []struct {
+		name      string
+		url       model.URL
+		want      string
+		wantClass string
+	}{
+		{name: "overall server status", url: grpcURL(addr, nil), want: Healthy},
+		{name: "serving service", url: grpcURL(addr, &model.GRPCCheck{Service: "orders.v1.Orders"}), want: Healthy},
+		{
+			name:      "not serving service",
+			url:       grpcURL(addr, &model.GRPCCheck{Service: "billing.v1.Billing"}),
+			want:      UnHealthy,
+			wantClass: model.ErrorClassAssertion,
+		},
+		{
+			name:      "unknown service",
+			url:       grpcURL(addr, &model.GRPCCheck{Service: "nope.v1.Nope"}),
+			want:      UnHealthy,
+			wantClass: model.ErrorClassAssertion,
+		},
+		{name: "tls", url: grpcURL(tlsAddr, &model.GRPCCheck{TLS: true}), want: Healthy},
+		{
+			name:      "tls with the wrong server name",
+			url:       grpcURL(tlsAddr, &model.GRPCCheck{TLS: true, ServerName: "other.test"}),
+			want:      UnHealthy,
+			wantClass: model.ErrorClassTLS,
+		},
+		{
+			name:      "connection refused",
+			url:       grpcURL("127.0.0.1:1", nil),
+			want:      UnHealthy,
+			wantClass: model.ErrorClassRefused,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := uc.ping(context.Background(), tt.url)
+			if got.Status != tt.want {
+				t.Errorf("ping() status = %v, want %v (error %q, failures %v)", got.Status, tt.want, got.Error, got.FailedAssertions)
+			}
+			if got.ErrorClass != tt.wantClass {
+				t.Errorf("ping() error class = %q, want %q", got.ErrorClass, tt.wantClass)
+			}
+		})
+	}
+
+	// Status changes are picked up on the next check
+	hs.SetServingStatus("orders.v1.Orders", healthpb.HealthCheckResponse_NOT_SERVING)
+	if got := uc.ping(context.Background(), grpcURL(addr, &model.GRPCCheck{Service: "orders.v1.Orders"})); got.Status != UnHealthy {
+		t.Errorf("ping() after NOT_SERVING = %v, want %v", got.Status, UnHealthy)
+	}
+}
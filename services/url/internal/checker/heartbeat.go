@@ -0,0 +1,165 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/kafka"
+	"github.com/samims/hcaas/services/url/internal/metrics"
+	"github.com/samims/hcaas/services/url/internal/model"
+	"github.com/samims/hcaas/services/url/internal/service"
+)
+
+// maxPingOutput caps how much job output is kept with a failed ping
+const maxPingOutput = 1000
+
+// checkHeartbeat records a missed heartbeat when no ping arrived within the
+// period plus grace. Pings are recorded by the API, so state always comes from
+// storage. It returns the time the next deadline is measured from.
+func (uc *URLChecker) checkHeartbeat(ctx context.Context, url model.URL) time.Time {
+	url, ok := uc.reload(ctx, url)
+	if !ok {
+		return time.Now()
+	}
+	grace := heartbeatGrace(url)
+	// Missed checks don't count as pings, so each miss is measured from the
+	// last real ping, or from creation until the first one
+	lastSeen := url.CreatedAt
+	if url.LastPingAt != nil {
+		lastSeen = *url.LastPingAt
+	}
+	now := time.Now()
+	if now.Before(lastSeen.Add(checkInterval(url) + grace)) {
+		return lastSeen.Add(grace)
+	}
+
+	result := model.CheckResult{
+		URLID:      url.ID,
+		CheckedAt:  now,
+		Status:     UnHealthy,
+		Error:      fmt.Sprintf("no ping received since %s", lastSeen.UTC().Format(time.RFC3339)),
+		ErrorClass: model.ErrorClassMissedHeartbeat,
+	}
+	metrics.URLCheckStatus.WithLabelValues(model.StatusDown).Inc()
+	uc.record(ctx, url, stateFromURL(url), result)
+	return now.Add(grace)
+}
+
+func heartbeatGrace(url model.URL) time.Duration {
+	if url.Heartbeat == nil {
+		return model.DefaultHeartbeatGrace.Std()
+	}
+	return url.Heartbeat.Grace.Std()
+}
+
+// HeartbeatReceiver records check-ins from push-based heartbeat monitors
+type HeartbeatReceiver interface {
+	Receive(ctx context.Context, token string, ping model.HeartbeatPing) error
+}
+
+type heartbeatReceiver struct {
+	svc                  service.URLService
+	notificationProducer kafka.NotificationProducer
+	logger               *slog.Logger
+}
+
+func NewHeartbeatReceiver(svc service.URLService, producer kafka.NotificationProducer, logger *slog.Logger) HeartbeatReceiver {
+	if producer == nil {
+		panic("NewHeartbeatReceiver: notificationProducer cannot be nil")
+	}
+	return &heartbeatReceiver{
+		svc:                  svc,
+		notificationProducer: producer,
+		logger:               logger.With("component", "heartbeatReceiver"),
+	}
+}
+
+// maxPingAttempts bounds how often a ping is reapplied when a concurrent ping
+// or missed-heartbeat check changed its monitor first
+const maxPingAttempts = 3
+
+// Receive applies a ping to its monitor. A start only marks the run as begun;
+// success, failure and exit codes are recorded as check results, with the run
+// duration as latency when the job signalled its start.
+func (r *heartbeatReceiver) Receive(ctx context.Context, token string, ping model.HeartbeatPing) error {
+	for attempt := 1; ; attempt++ {
+		err := r.receive(ctx, token, ping)
+		if !appErr.IsConflict(err) || attempt == maxPingAttempts {
+			return err
+		}
+	}
+}
+
+// receive loads the monitor and applies one ping to it
+func (r *heartbeatReceiver) receive(ctx context.Context, token string, ping model.HeartbeatPing) error {
+	url, err := r.svc.GetByHeartbeatToken(ctx, token)
+	if err != nil {
+		return err
+	}
+	// Jobs keep running while their monitor is paused; their pings are ignored
+	if url.Paused {
+		r.logger.Info("Heartbeat ignored, monitor paused", slog.String("url_id", url.ID))
+		return nil
+	}
+	now := time.Now()
+	prev := stateFromURL(*url)
+
+	if ping.Kind == model.HeartbeatStart {
+		r.logger.Info("Heartbeat run started", slog.String("url_id", url.ID))
+		return r.svc.MarkRunStarted(ctx, url.ID, now)
+	}
+
+	result := heartbeatResult(prev.counters.RunStartedAt, ping, now)
+	result.URLID = url.ID
+	prev.counters.RunStartedAt = nil
+
+	next, notifications, err := applyResult(ctx, r.svc, *url, prev, &result, nil)
+	if err != nil {
+		return err
+	}
+	r.logger.Info("Heartbeat received",
+		slog.String("url_id", url.ID),
+		slog.String("kind", ping.Kind),
+		slog.String("status", next.status))
+
+	if result.Status == Healthy {
+		metrics.URLCheckStatus.WithLabelValues(model.StatusUP).Inc()
+	} else {
+		metrics.URLCheckStatus.WithLabelValues(model.StatusDown).Inc()
+	}
+	for _, notification := range notifications {
+		publish(ctx, r.notificationProducer, r.logger, notification)
+	}
+	return nil
+}
+
+// heartbeatResult turns a completion ping into a check result
+func heartbeatResult(startedAt *time.Time, ping model.HeartbeatPing, now time.Time) model.CheckResult {
+	result := model.CheckResult{CheckedAt: now, Status: Healthy}
+	if startedAt != nil && startedAt.Before(now) {
+		result.Latency = model.Duration(now.Sub(*startedAt))
+	}
+
+	switch {
+	case ping.Kind == model.HeartbeatFail:
+		result.Error = "job reported failure"
+	case ping.Kind == model.HeartbeatExitCode && ping.ExitCode != 0:
+		result.Error = fmt.Sprintf("job exited with code %d", ping.ExitCode)
+	default:
+		return result
+	}
+
+	result.Status = UnHealthy
+	result.ErrorClass = model.ErrorClassJobFailed
+	if ping.Body != "" {
+		output := ping.Body
+		if len(output) > maxPingOutput {
+			output = output[:maxPingOutput] + "..."
+		}
+		result.Error += ": " + output
+	}
+	return result
+}
@@ -0,0 +1,208 @@
+package checker
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+	"github.com/samims/hcaas/services/url/internal/service"
+)
+
+// memURLService keeps a single URL in memory; methods the tests don't use panic
+// through the embedded nil interface
+type memURLService struct {
+	service.URLService
+	url     model.URL
+	results []model.CheckResult
+}
+
+func (m *memURLService) GetForCheck(_ context.Context, id string) (*model.URL, error) {
+	if id != m.url.ID {
+		return nil, appErr.NewNotFound("url not found")
+	}
+	url := m.url
+	return &url, nil
+}
+
+func (m *memURLService) GetByHeartbeatToken(_ context.Context, token string) (*model.URL, error) {
+	if m.url.Heartbeat == nil || token != m.url.Heartbeat.Token {
+		return nil, appErr.NewNotFound("heartbeat not found")
+	}
+	url := m.url
+	return &url, nil
+}
+
+func (m *memURLService) RecordCheck(_ context.Context, result *model.CheckResult, status string, state model.CheckState) error {
+	if status != m.url.Status {
+		m.url.StatusChangedAt = result.CheckedAt
+	}
+	m.url.Status = status
+	m.url.CheckedAt = result.CheckedAt
+	m.url.State = state
+	m.results = append(m.results, *result)
+	return nil
+}
+
+func (m *memURLService) RecordHeartbeat(ctx context.Context, result *model.CheckResult, status string, state model.CheckState, loaded model.URL) error {
+	if !loaded.CheckedAt.Equal(m.url.CheckedAt) || loaded.State.RunStartedAt != m.url.State.RunStartedAt {
+		return appErr.NewConflict("url changed")
+	}
+	if result.ErrorClass != model.ErrorClassMissedHeartbeat {
+		m.url.LastPingAt = &result.CheckedAt
+	}
+	return m.RecordCheck(ctx, result, status, state)
+}
+
+func (m *memURLService) MarkRunStarted(_ context.Context, _ string, at time.Time) error {
+	m.url.State.RunStartedAt = &at
+	return nil
+}
+
+// memProducer collects published notifications
+type memProducer struct{ published []model.Notification }
+
+func (p *memProducer) Start(context.Context) {}
+func (p *memProducer) Close(context.Context) {}
+func (p *memProducer) Publish(_ context.Context, n model.Notification) error {
+	p.published = append(p.published, n)
+	return nil
+}
+
+func Test_heartbeatResult(t *testing.T) {
+	now := time.Date(2025, 7, 1, 3, 0, 0, 0, time.UTC)
+	started := now.Add(-90 * time.Second)
+
+	tests := []struct {
+		name        string
+		startedAt   *time.Time
+		ping        model.HeartbeatPing
+		wantStatus  string
+		wantLatency time.Duration
+		wantError   string
+	}{
+		{name: "plain success", ping: model.HeartbeatPing{Kind: model.HeartbeatSuccess}, wantStatus: Healthy},
+		{
+			name:        "success after start tracks duration",
+			startedAt:   &started,
+			ping:        model.HeartbeatPing{Kind: model.HeartbeatSuccess},
+			wantStatus:  Healthy,
+			wantLatency: 90 * time.Second,
+		},
+		{name: "exit code zero", ping: model.HeartbeatPing{Kind: model.HeartbeatExitCode}, wantStatus: Healthy},
+		{
+			name:       "non-zero exit code",
+			ping:       model.HeartbeatPing{Kind: model.HeartbeatExitCode, ExitCode: 2},
+			wantStatus: UnHealthy,
+			wantError:  "job exited with code 2",
+		},
+		{
+			name:       "failure with output",
+			ping:       model.HeartbeatPing{Kind: model.HeartbeatFail, Body: "disk full"},
+			wantStatus: UnHealthy,
+			wantError:  "job reported failure: disk full",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := heartbeatResult(tt.startedAt, tt.ping, now)
+			if got.Status != tt.wantStatus || got.Latency.Std() != tt.wantLatency || got.Error != tt.wantError {
+				t.Errorf("heartbeatResult() = %s/%s/%q, want %s/%s/%q",
+					got.Status, got.Latency.Std(), got.Error, tt.wantStatus, tt.wantLatency, tt.wantError)
+			}
+		})
+	}
+}
+
+// Test_heartbeat_flow misses a ping, recovers, then fails through an exit code
+func Test_heartbeat_flow(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := &memURLService{url: model.URL{
+		ID:        "job-1",
+		Type:      model.MonitorHeartbeat,
+		Address:   "/heartbeat/tok",
+		Status:    model.StatusUnknown,
+		Interval:  model.Duration(time.Hour),
+		Heartbeat: &model.HeartbeatCheck{Token: "tok", Grace: model.Duration(5 * time.Minute)},
+		CreatedAt: time.Now().Add(-30 * time.Minute),
+	}}
+	producer := &memProducer{}
+	uc := &URLChecker{svc: svc, logger: logger, notificationProducer: producer, states: newStateTracker(), sched: newScheduler(0)}
+	receiver := NewHeartbeatReceiver(svc, producer, logger)
+
+	// Within the period nothing is recorded and the next deadline follows creation
+	if next := uc.checkURL(ctx, svc.url); !next.Equal(svc.url.CreatedAt.Add(5 * time.Minute)) {
+		t.Errorf("checkURL() next = %v, want created + grace", next)
+	}
+	if len(svc.results) != 0 {
+		t.Fatalf("recorded %d results before the deadline, want 0", len(svc.results))
+	}
+
+	// Overdue: a missed heartbeat takes it down
+	lastPing := time.Now().Add(-66 * time.Minute).Truncate(time.Second)
+	svc.url.LastPingAt = &lastPing
+	uc.checkURL(ctx, svc.url)
+	if svc.url.Status != UnHealthy || svc.results[0].ErrorClass != model.ErrorClassMissedHeartbeat {
+		t.Fatalf("after missed ping status = %s class = %s, want unhealthy missed_heartbeat", svc.url.Status, svc.results[0].ErrorClass)
+	}
+
+	// The next miss is still measured from the last real ping, not the missed check
+	uc.checkURL(ctx, svc.url)
+	if len(svc.results) != 2 || !strings.Contains(svc.results[1].Error, lastPing.UTC().Format(time.RFC3339)) {
+		t.Fatalf("second miss results = %+v, want another miss since %v", svc.results, lastPing)
+	}
+	if !svc.url.LastPingAt.Equal(lastPing) {
+		t.Errorf("LastPingAt = %v after misses, want %v", svc.url.LastPingAt, lastPing)
+	}
+
+	// The job checks in again
+	if err := receiver.Receive(ctx, "tok", model.HeartbeatPing{Kind: model.HeartbeatSuccess}); err != nil {
+		t.Fatalf("Receive() error = %v", err)
+	}
+	if svc.url.Status != Healthy || !svc.url.LastPingAt.Equal(svc.url.CheckedAt) {
+		t.Errorf("after ping status = %s last ping = %v, want healthy at %v", svc.url.Status, svc.url.LastPingAt, svc.url.CheckedAt)
+	}
+
+	// A miss computed from a stale load loses to the ping recorded since
+	stale := svc.url
+	stale.CheckedAt = stale.CheckedAt.Add(-time.Hour)
+	_, _, err := applyResult(ctx, svc, stale, stateFromURL(stale), &model.CheckResult{
+		URLID: stale.ID, CheckedAt: time.Now(), Status: UnHealthy, ErrorClass: model.ErrorClassMissedHeartbeat,
+	}, nil)
+	if !appErr.IsConflict(err) || svc.url.Status != Healthy {
+		t.Errorf("stale miss error = %v status = %s, want conflict and still healthy", err, svc.url.Status)
+	}
+
+	// A run that starts and exits non-zero goes down with its duration recorded
+	if err := receiver.Receive(ctx, "tok", model.HeartbeatPing{Kind: model.HeartbeatStart}); err != nil {
+		t.Fatalf("Receive(start) error = %v", err)
+	}
+	if svc.url.State.RunStartedAt == nil {
+		t.Fatal("start was not remembered")
+	}
+	if err := receiver.Receive(ctx, "tok", model.HeartbeatPing{Kind: model.HeartbeatExitCode, ExitCode: 1}); err != nil {
+		t.Fatalf("Receive(exit code) error = %v", err)
+	}
+	last := svc.results[len(svc.results)-1]
+	if svc.url.Status != UnHealthy || last.ErrorClass != model.ErrorClassJobFailed || svc.url.State.RunStartedAt != nil {
+		t.Errorf("after exit code 1 status = %s class = %s run_started_at = %v", svc.url.Status, last.ErrorClass, svc.url.State.RunStartedAt)
+	}
+
+	var types []string
+	for _, n := range producer.published {
+		types = append(types, n.Type)
+	}
+	want := []string{model.NotificationURLDown, model.NotificationURLRecovered, model.NotificationURLDown}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("notifications = %v, want %v", types, want)
+	}
+
+	if err := receiver.Receive(ctx, "nope", model.HeartbeatPing{Kind: model.HeartbeatSuccess}); !appErr.IsNotFound(err) {
+		t.Errorf("Receive(unknown token) error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,81 @@
+package checker
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// Check outcomes counted by URLCheckOutcomes
+const (
+	outcomeFirstTry   = "first_try"
+	outcomeAfterRetry = "after_retry"
+	outcomeFailed     = "failed"
+)
+
+// pingWithRetry runs the check, retrying retryable failures as the URL's
+// policy allows. The result is the last attempt's, with Attempts set.
+func (uc *URLChecker) pingWithRetry(ctx context.Context, url model.URL) model.CheckResult {
+	result := uc.pingOnce(ctx, url)
+	result.Attempts = 1
+	policy := url.Retry
+	if policy == nil {
+		return result
+	}
+
+	for n := 0; n < policy.Count && retryable(policy, result); n++ {
+		delay := model.RetryDelay(policy, n).Std()
+		uc.logger.Info("Retrying check",
+			slog.String("url_id", url.ID),
+			slog.String("address", url.Address),
+			slog.Int("attempt", result.Attempts+1),
+			slog.String("error_class", result.ErrorClass),
+			slog.Int("statusCode", result.StatusCode),
+			slog.Duration("backoff", delay),
+		)
+		if !sleepCtx(ctx, delay) {
+			break
+		}
+		attempts := result.Attempts
+		result = uc.pingOnce(ctx, url)
+		result.Attempts = attempts + 1
+	}
+	return result
+}
+
+// retryable reports whether a failed attempt is one the policy retries
+func retryable(policy *model.RetryPolicy, result model.CheckResult) bool {
+	if result.Status == Healthy {
+		return false
+	}
+	if slices.Contains(policy.ErrorClasses, result.ErrorClass) {
+		return true
+	}
+	return result.StatusCode != 0 && slices.Contains(policy.StatusCodes, result.StatusCode)
+}
+
+// checkOutcome tells first-try successes from ones that needed retries
+func checkOutcome(result model.CheckResult) string {
+	switch {
+	case result.Status != Healthy:
+		return outcomeFailed
+	case result.Attempts > 1:
+		return outcomeAfterRetry
+	}
+	return outcomeFirstTry
+}
+
+// sleepCtx waits for d, returning false if ctx is done first
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,111 @@
+package checker
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// Test_URLChecker_ping_retry checks failures are retried only when the policy
+// covers them, and the attempts are counted.
+// Table Driven Test Pattern used
+func Test_URLChecker_ping_retry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first two requests of each case hit a 503
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	uc := &URLChecker{logger: slog.Default(), httpClient: server.Client()}
+
+	tests := []struct {
+		name         string
+		address      string
+		retry        *model.RetryPolicy
+		want         string
+		wantAttempts int
+		wantOutcome  string
+	}{
+		{
+			name:         "no policy fails on the first attempt",
+			retry:        nil,
+			want:         UnHealthy,
+			wantAttempts: 1,
+			wantOutcome:  outcomeFailed,
+		},
+		{
+			name:         "status code retried until it passes",
+			retry:        &model.RetryPolicy{Count: 3, StatusCodes: []int{http.StatusServiceUnavailable}},
+			want:         Healthy,
+			wantAttempts: 3,
+			wantOutcome:  outcomeAfterRetry,
+		},
+		{
+			name:         "retries used up",
+			retry:        &model.RetryPolicy{Count: 1, StatusCodes: []int{http.StatusServiceUnavailable}},
+			want:         UnHealthy,
+			wantAttempts: 2,
+			wantOutcome:  outcomeFailed,
+		},
+		{
+			name:         "status code not covered by the policy",
+			retry:        &model.RetryPolicy{Count: 3, ErrorClasses: []string{model.ErrorClassConnection}},
+			want:         UnHealthy,
+			wantAttempts: 1,
+			wantOutcome:  outcomeFailed,
+		},
+		{
+			name:         "error class retried",
+			address:      "http://127.0.0.1:1/",
+			retry:        &model.RetryPolicy{Count: 2, ErrorClasses: []string{model.ErrorClassRefused}},
+			want:         UnHealthy,
+			wantAttempts: 3,
+			wantOutcome:  outcomeFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			address := tt.address
+			if address == "" {
+				address = server.URL + "/"
+			}
+			got := uc.ping(context.Background(), model.URL{Address: address, Retry: tt.retry})
+			if got.Status != tt.want {
+				t.Errorf("ping() status = %v, want %v", got.Status, tt.want)
+			}
+			if got.Attempts != tt.wantAttempts {
+				t.Errorf("ping() attempts = %d, want %d", got.Attempts, tt.wantAttempts)
+			}
+			if outcome := checkOutcome(got); outcome != tt.wantOutcome {
+				t.Errorf("checkOutcome() = %q, want %q", outcome, tt.wantOutcome)
+			}
+		})
+	}
+}
+
+func Test_RetryDelay(t *testing.T) {
+	policy := &model.RetryPolicy{Backoff: model.Duration(10 * time.Second)}
+	want := []model.Duration{
+		model.Duration(10 * time.Second),
+		model.Duration(20 * time.Second),
+		model.MaxRetryBackoff,
+		model.MaxRetryBackoff,
+	}
+	for n, w := range want {
+		if got := model.RetryDelay(policy, n); got != w {
+			t.Errorf("RetryDelay(%d) = %s, want %s", n, got.Std(), w.Std())
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package checker
+
+import (
+	"container/heap"
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// scheduleItem is one monitor in the scheduler. index is its heap position,
+// or -1 while a check for it is in flight.
+type scheduleItem struct {
+	url     model.URL
+	due     time.Time
+	index   int
+	removed bool
+}
+
+// scheduleQueue is a min-heap of items ordered by due time
+type scheduleQueue []*scheduleItem
+
+func (q scheduleQueue) Len() int           { return len(q) }
+func (q scheduleQueue) Less(i, j int) bool { return q[i].due.Before(q[j].due) }
+func (q scheduleQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+func (q *scheduleQueue) Push(x any) {
+	item := x.(*scheduleItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+func (q *scheduleQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*q = old[:n-1]
+	return item
+}
+
+// scheduler hands out monitors when they are due. Each monitor runs on its own
+// interval; jitter spreads runs so monitors added together don't fire together.
+type scheduler struct {
+	mu     sync.Mutex
+	queue  scheduleQueue
+	items  map[string]*scheduleItem
+	wake   chan struct{}
+	jitter float64
+	rand   *rand.Rand
+	now    func() time.Time
+}
+
+func newScheduler(jitter float64) *scheduler {
+	return &scheduler{
+		items:  make(map[string]*scheduleItem),
+		wake:   make(chan struct{}, 1),
+		jitter: jitter,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:    time.Now,
+	}
+}
+
+// upsert adds a monitor or refreshes the config of a known one. A monitor whose
+// interval changed is rescheduled from its last check.
+func (s *scheduler) upsert(url model.URL) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[url.ID]
+	if !ok {
+		item = &scheduleItem{url: url, due: s.firstDue(url)}
+		s.items[url.ID] = item
+		heap.Push(&s.queue, item)
+		s.signal()
+		return
+	}
+
+	intervalChanged := item.url.Interval != url.Interval
+	item.url = url
+	if item.index < 0 {
+		// in flight: done() reschedules with the fresh config
+		return
+	}
+	if intervalChanged {
+		item.due = s.nextDue(url, url.CheckedAt)
+		heap.Fix(&s.queue, item.index)
+		s.signal()
+	}
+}
+
+// remove drops a monitor; an in-flight check is not rescheduled
+func (s *scheduler) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[id]
+	if !ok {
+		return
+	}
+	delete(s.items, id)
+	item.removed = true
+	if item.index >= 0 {
+		heap.Remove(&s.queue, item.index)
+	}
+}
+
+// next blocks until the earliest monitor is due and takes it out of the queue.
+// The caller must call done once the check finished. ok is false when ctx ends.
+func (s *scheduler) next(ctx context.Context) (url model.URL, due time.Time, ok bool) {
+	for {
+		s.mu.Lock()
+		var wait time.Duration = -1
+		if len(s.queue) > 0 {
+			head := s.queue[0]
+			wait = head.due.Sub(s.now())
+			if wait <= 0 {
+				heap.Pop(&s.queue)
+				s.mu.Unlock()
+				return head.url, head.due, true
+			}
+		}
+		s.mu.Unlock()
+
+		var (
+			timer *time.Timer
+			fire  <-chan time.Time
+		)
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			fire = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			ok = false
+		case <-s.wake:
+			ok = true
+		case <-fire:
+			ok = true
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if !ok {
+			return model.URL{}, time.Time{}, false
+		}
+	}
+}
+
+// done puts a monitor back in the queue after a check that ran at checkedAt
+func (s *scheduler) done(id string, checkedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[id]
+	if !ok || item.removed || item.index >= 0 {
+		return
+	}
+	item.due = s.nextDue(item.url, checkedAt)
+	heap.Push(&s.queue, item)
+	s.signal()
+}
+
+// len returns the number of known monitors, queued or in flight
+func (s *scheduler) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+// firstDue places a new monitor: where its last check says, or spread over its
+// interval when it's overdue so a restart doesn't check everything at once.
+func (s *scheduler) firstDue(url model.URL) time.Time {
+	now := s.now()
+	interval := checkInterval(url)
+	if !url.CheckedAt.IsZero() {
+		if due := url.CheckedAt.Add(interval); due.After(now) {
+			return due
+		}
+	}
+	return now.Add(time.Duration(s.rand.Int63n(int64(interval)/10 + 1)))
+}
+
+// nextDue is one interval after from, shifted by up to ±jitter of the interval
+func (s *scheduler) nextDue(url model.URL, from time.Time) time.Time {
+	interval := checkInterval(url)
+	if from.IsZero() {
+		from = s.now()
+	}
+	due := from.Add(interval)
+	// Heartbeat deadlines are exact; jitter would only delay spotting a missed ping
+	if s.jitter > 0 && url.Type != model.MonitorHeartbeat {
+		spread := float64(interval) * s.jitter
+		due = due.Add(time.Duration((s.rand.Float64()*2 - 1) * spread))
+	}
+	if now := s.now(); due.Before(now) {
+		return now
+	}
+	return due
+}
+
+// signal wakes a blocked next; callers hold s.mu
+func (s *scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func checkInterval(url model.URL) time.Duration {
+	if url.Interval <= 0 {
+		return model.DefaultCheckInterval.Std()
+	}
+	return url.Interval.Std()
+}
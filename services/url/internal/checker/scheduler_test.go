@@ -0,0 +1,96 @@
+package checker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+func Test_scheduler_order(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	s := newScheduler(0)
+	s.now = func() time.Time { return now }
+
+	// Three overdue monitors and one that isn't due for ten minutes
+	s.upsert(model.URL{ID: "c", Interval: model.Duration(time.Minute), CheckedAt: now.Add(-61 * time.Second)})
+	s.upsert(model.URL{ID: "a", Interval: model.Duration(10 * time.Second), CheckedAt: now.Add(-13 * time.Second)})
+	s.upsert(model.URL{ID: "b", Interval: model.Duration(15 * time.Minute), CheckedAt: now.Add(-15*time.Minute - 2*time.Second)})
+	s.upsert(model.URL{ID: "later", Interval: model.Duration(10 * time.Minute), CheckedAt: now})
+
+	// Overdue monitors are spread over a tenth of their interval, so move the
+	// clock past that before draining.
+	now = now.Add(2 * time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		url, _, ok := s.next(ctx)
+		if !ok {
+			t.Fatalf("next() returned early after %d items", i)
+		}
+		seen[url.ID] = true
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if !seen[id] {
+			t.Errorf("expected %s to be dispatched before later", id)
+		}
+	}
+
+	if len(s.queue) != 1 || s.queue[0].url.ID != "later" {
+		t.Fatalf("expected only later to remain queued, got %d items", len(s.queue))
+	}
+}
+
+func Test_scheduler_doneAndRemove(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	s := newScheduler(0.1)
+	s.now = func() time.Time { return now }
+
+	url := model.URL{ID: "a", Interval: model.Duration(time.Minute)}
+	s.upsert(url)
+
+	item := s.items["a"]
+	if item.due.Before(now) || item.due.After(now.Add(6*time.Second)) {
+		t.Fatalf("first due = %v, want within the first tenth of the interval", item.due)
+	}
+
+	now = now.Add(10 * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, _, ok := s.next(ctx); !ok {
+		t.Fatal("next() did not return the due monitor")
+	}
+	if item.index != -1 {
+		t.Fatalf("in-flight item still queued at %d", item.index)
+	}
+
+	// A config change while in flight is picked up when it is rescheduled
+	s.upsert(model.URL{ID: "a", Interval: model.Duration(10 * time.Minute)})
+	s.done("a", now)
+	if lo, hi := now.Add(9*time.Minute), now.Add(11*time.Minute); item.due.Before(lo) || item.due.After(hi) {
+		t.Errorf("due after done = %v, want within [%v, %v]", item.due, lo, hi)
+	}
+
+	s.remove("a")
+	if s.len() != 0 || len(s.queue) != 0 {
+		t.Errorf("after remove: len = %d, queue = %d", s.len(), len(s.queue))
+	}
+	s.done("a", now)
+	if len(s.queue) != 0 {
+		t.Errorf("removed monitor was rescheduled")
+	}
+}
+
+func Test_scheduler_nextBlocksUntilCancelled(t *testing.T) {
+	s := newScheduler(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, _, ok := s.next(ctx); ok {
+		t.Fatal("next() on an empty schedule should block until ctx ends")
+	}
+}
@@ -0,0 +1,178 @@
+package checker
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/config"
+	"github.com/samims/hcaas/services/url/internal/metrics"
+	"github.com/samims/hcaas/services/url/internal/storage"
+)
+
+// shardOf maps a URL id onto one of n shards
+func shardOf(id string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32() % uint32(n))
+}
+
+// shardCoordinator keeps this replica's share of shard leases. Every heartbeat it
+// renews what it holds, releases shards above its fair share and claims free or
+// expired ones below it, so work rebalances when replicas join or die.
+type shardCoordinator struct {
+	store  storage.LeaseStorage
+	cfg    config.CheckerConfig
+	logger *slog.Logger
+
+	mu         sync.RWMutex
+	owned      map[int]bool
+	validUntil time.Time
+
+	// onLost is called for every shard this replica stops owning
+	onLost func(shard int)
+}
+
+func newShardCoordinator(store storage.LeaseStorage, cfg config.CheckerConfig, logger *slog.Logger) *shardCoordinator {
+	return &shardCoordinator{
+		store:  store,
+		cfg:    cfg,
+		logger: logger.With("component", "shardCoordinator", "instance", cfg.InstanceID),
+		owned:  make(map[int]bool),
+	}
+}
+
+// owns reports whether this replica currently holds the lease for the URL's shard.
+// Leases that couldn't be renewed in time are treated as lost.
+func (c *shardCoordinator) owns(urlID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if time.Now().After(c.validUntil) {
+		return false
+	}
+	return c.owned[shardOf(urlID, c.cfg.Shards)]
+}
+
+// run heartbeats until ctx ends, then hands its leases back
+func (c *shardCoordinator) run(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.leave()
+			return
+		case <-ticker.C:
+			c.heartbeat(ctx)
+		}
+	}
+}
+
+// heartbeat renews, trims and tops up this replica's leases
+func (c *shardCoordinator) heartbeat(ctx context.Context) {
+	started := time.Now()
+	ttl := c.cfg.LeaseTTL
+
+	live, err := c.store.Heartbeat(ctx, c.cfg.InstanceID, ttl)
+	if err != nil {
+		c.logger.Error("Heartbeat failed", slog.Any("error", err))
+		return
+	}
+	if err := c.store.EnsureShards(ctx, c.cfg.Shards); err != nil {
+		c.logger.Error("Failed to ensure shards", slog.Any("error", err))
+		return
+	}
+
+	held, err := c.store.RenewLeases(ctx, c.cfg.InstanceID, ttl)
+	if err != nil {
+		c.logger.Error("Failed to renew leases", slog.Any("error", err))
+		return
+	}
+	held = inRange(held, c.cfg.Shards)
+
+	fair := (c.cfg.Shards + live - 1) / max(live, 1)
+	switch {
+	case len(held) > fair:
+		sort.Ints(held)
+		extra := held[fair:]
+		if err := c.store.ReleaseLeases(ctx, c.cfg.InstanceID, extra); err != nil {
+			c.logger.Error("Failed to release leases", slog.Any("error", err))
+		} else {
+			held = held[:fair]
+		}
+	case len(held) < fair:
+		acquired, err := c.store.AcquireLeases(ctx, c.cfg.InstanceID, ttl, c.cfg.Shards, fair-len(held))
+		if err != nil {
+			c.logger.Error("Failed to acquire leases", slog.Any("error", err))
+		}
+		held = append(held, acquired...)
+	}
+
+	c.update(held, started.Add(ttl))
+	c.logger.Debug("Heartbeat done", slog.Int("live_instances", live), slog.Int("shards", len(held)))
+}
+
+// update swaps in the new owned set and reports shards that were lost
+func (c *shardCoordinator) update(held []int, validUntil time.Time) {
+	owned := make(map[int]bool, len(held))
+	for _, s := range held {
+		owned[s] = true
+	}
+
+	c.mu.Lock()
+	var lost []int
+	for s := range c.owned {
+		if !owned[s] {
+			lost = append(lost, s)
+		}
+	}
+	gained := 0
+	for s := range owned {
+		if !c.owned[s] {
+			gained++
+		}
+	}
+	c.owned = owned
+	c.validUntil = validUntil
+	c.mu.Unlock()
+
+	metrics.CheckerOwnedShards.Set(float64(len(owned)))
+	if len(lost) > 0 || gained > 0 {
+		c.logger.Info("Shard ownership changed",
+			slog.Int("owned", len(owned)),
+			slog.Int("gained", gained),
+			slog.Int("lost", len(lost)))
+	}
+	if c.onLost != nil {
+		for _, s := range lost {
+			c.onLost(s)
+		}
+	}
+}
+
+// leave releases all leases so other replicas can pick them up immediately
+func (c *shardCoordinator) leave() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c.update(nil, time.Time{})
+	if err := c.store.Leave(ctx, c.cfg.InstanceID); err != nil {
+		c.logger.Error("Failed to release leases on shutdown", slog.Any("error", err))
+		return
+	}
+	c.logger.Info("Released all shard leases")
+}
+
+func inRange(shards []int, n int) []int {
+	out := shards[:0]
+	for _, s := range shards {
+		if s < n {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,123 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/config"
+)
+
+// memLeases is an in-memory LeaseStorage where every registered instance is live
+type memLeases struct {
+	instances map[string]bool
+	owner     map[int]string
+}
+
+func newMemLeases() *memLeases {
+	return &memLeases{instances: map[string]bool{}, owner: map[int]string{}}
+}
+
+func (m *memLeases) Heartbeat(_ context.Context, id string, _ time.Duration) (int, error) {
+	m.instances[id] = true
+	return len(m.instances), nil
+}
+
+func (m *memLeases) EnsureShards(context.Context, int) error { return nil }
+
+func (m *memLeases) RenewLeases(_ context.Context, id string, _ time.Duration) ([]int, error) {
+	var held []int
+	for s, owner := range m.owner {
+		if owner == id {
+			held = append(held, s)
+		}
+	}
+	return held, nil
+}
+
+func (m *memLeases) AcquireLeases(_ context.Context, id string, _ time.Duration, shards, n int) ([]int, error) {
+	var got []int
+	for s := 0; s < shards && len(got) < n; s++ {
+		if m.owner[s] == "" {
+			m.owner[s] = id
+			got = append(got, s)
+		}
+	}
+	return got, nil
+}
+
+func (m *memLeases) ReleaseLeases(_ context.Context, id string, shards []int) error {
+	for _, s := range shards {
+		if m.owner[s] == id {
+			delete(m.owner, s)
+		}
+	}
+	return nil
+}
+
+func (m *memLeases) Leave(_ context.Context, id string) error {
+	delete(m.instances, id)
+	for s, owner := range m.owner {
+		if owner == id {
+			delete(m.owner, s)
+		}
+	}
+	return nil
+}
+
+func Test_shardCoordinator_rebalance(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := newMemLeases()
+
+	newCoordinator := func(id string) *shardCoordinator {
+		cfg := config.CheckerConfig{InstanceID: id, Shards: 8, LeaseTTL: time.Minute}
+		return newShardCoordinator(store, cfg, logger)
+	}
+	count := func(c *shardCoordinator) int {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		return len(c.owned)
+	}
+
+	a := newCoordinator("a")
+	a.heartbeat(ctx)
+	if got := count(a); got != 8 {
+		t.Fatalf("single instance owns %d shards, want 8", got)
+	}
+
+	// A second replica joins: a gives up half, b picks them up
+	b := newCoordinator("b")
+	var lost []int
+	a.onLost = func(s int) { lost = append(lost, s) }
+	b.heartbeat(ctx)
+	a.heartbeat(ctx)
+	b.heartbeat(ctx)
+	if count(a) != 4 || count(b) != 4 {
+		t.Fatalf("after join a=%d b=%d, want 4/4", count(a), count(b))
+	}
+	if len(lost) != 4 {
+		t.Errorf("a reported %d lost shards, want 4", len(lost))
+	}
+
+	// Every URL is owned by exactly one replica
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("url-%d", i)
+		if a.owns(id) == b.owns(id) {
+			t.Fatalf("%s: owned by a=%v b=%v", id, a.owns(id), b.owns(id))
+		}
+	}
+
+	// b leaves: a takes everything back
+	b.leave()
+	a.heartbeat(ctx)
+	if got := count(a); got != 8 {
+		t.Errorf("after leave a owns %d shards, want 8", got)
+	}
+	if b.owns("url-1") {
+		t.Error("instance that left still owns shards")
+	}
+}
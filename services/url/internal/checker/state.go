@@ -0,0 +1,170 @@
+package checker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// monitorState is what the checker remembers about a URL between runs
+type monitorState struct {
+	status    string
+	changedAt time.Time
+	checkedAt time.Time
+	counters  model.CheckState
+}
+
+// stateTracker keeps the latest state of every URL this checker has run. The
+// stored URL is authoritative whenever it is newer, e.g. after a restart or
+// when another instance checked it last.
+type stateTracker struct {
+	mu     sync.Mutex
+	states map[string]monitorState
+}
+
+func newStateTracker() *stateTracker {
+	return &stateTracker{states: make(map[string]monitorState)}
+}
+
+func (t *stateTracker) get(url model.URL) monitorState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if st, ok := t.states[url.ID]; ok && !url.CheckedAt.After(st.checkedAt) {
+		return st
+	}
+	return stateFromURL(url)
+}
+
+// stateFromURL is the state as last persisted with the URL
+func stateFromURL(url model.URL) monitorState {
+	return monitorState{
+		status:    url.Status,
+		changedAt: url.StatusChangedAt,
+		checkedAt: url.CheckedAt,
+		counters:  url.State,
+	}
+}
+
+func (t *stateTracker) set(id string, st monitorState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.states[id] = st
+}
+
+func (t *stateTracker) forget(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.states, id)
+}
+
+// tracked reports whether the URL has been checked by this instance since it
+// was last (re)acquired
+func (t *stateTracker) tracked(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.states[id]
+	return ok
+}
+
+// resetContent drops the content hashes of a URL whose baseline was cleared,
+// so the next content seen becomes the new baseline
+func (t *stateTracker) resetContent(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if st, ok := t.states[id]; ok {
+		st.counters.ContentBaseline, st.counters.ContentChanged = "", ""
+		t.states[id] = st
+	}
+}
+
+// forgetIf drops the state of every URL matching fn
+func (t *stateTracker) forgetIf(fn func(id string) bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id := range t.states {
+		if fn(id) {
+			delete(t.states, id)
+		}
+	}
+}
+
+// nextState folds a raw check outcome into the URL's counters and returns the
+// new confirmed status. A URL only goes down after FailureThreshold consecutive
+// failures, only recovers after RecoveryThreshold consecutive successes, and is
+// reported as flapping while it changed status FlapThreshold times within FlapWindow.
+func nextState(url model.URL, prev monitorState, result model.CheckResult) monitorState {
+	next := prev
+	next.checkedAt = result.CheckedAt
+	c := prev.counters
+	c.RecentChanges = append([]time.Time(nil), prev.counters.RecentChanges...)
+
+	if result.Status == Healthy {
+		c.ConsecutiveSuccesses++
+		c.ConsecutiveFailures = 0
+	} else {
+		c.ConsecutiveFailures++
+		c.ConsecutiveSuccesses = 0
+	}
+
+	if c.Confirmed == "" && prev.status != model.StatusFlapping {
+		c.Confirmed = prev.status
+	}
+
+	confirmed := c.Confirmed
+	switch {
+	case confirmed != UnHealthy && c.ConsecutiveFailures >= threshold(url.FailureThreshold):
+		confirmed = UnHealthy
+	case confirmed != Healthy && c.ConsecutiveSuccesses >= threshold(url.RecoveryThreshold):
+		confirmed = Healthy
+	}
+	// Only healthy <-> unhealthy flips count towards flapping, not the first result
+	if confirmed != c.Confirmed && (c.Confirmed == Healthy || c.Confirmed == UnHealthy) {
+		c.RecentChanges = append(c.RecentChanges, result.CheckedAt)
+	}
+	c.Confirmed = confirmed
+
+	status := confirmed
+	if url.FlapThreshold > 0 {
+		window := url.FlapWindow.Std()
+		if window <= 0 {
+			window = model.DefaultFlapWindow.Std()
+		}
+		c.RecentChanges = pruneBefore(c.RecentChanges, result.CheckedAt.Add(-window))
+		if len(c.RecentChanges) >= url.FlapThreshold {
+			status = model.StatusFlapping
+		}
+	} else {
+		c.RecentChanges = nil
+	}
+	if status == "" {
+		status = model.StatusUnknown
+	}
+
+	if status != prev.status {
+		next.changedAt = result.CheckedAt
+	}
+	next.status = status
+	next.counters = c
+	return next
+}
+
+func threshold(n int) int {
+	if n <= 0 {
+		return model.DefaultThreshold
+	}
+	return n
+}
+
+// pruneBefore drops timestamps older than cutoff; times are kept in ascending order
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	if i == len(times) {
+		return nil
+	}
+	return times[i:]
+}
@@ -0,0 +1,114 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/assertion"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// maxBannerBytes caps how much a tcp monitor reads back from the server
+const maxBannerBytes = 64 << 10
+
+// pingTCP connects to host:port within the URL's timeout, optionally sends the
+// configured payload and checks what the server answers with
+func (uc *URLChecker) pingTCP(parentCtx context.Context, url model.URL) model.CheckResult {
+	target := url.Address
+
+	assertions, err := assertion.Compile(url.Assertions)
+	if err != nil {
+		uc.logger.Warn("Invalid assertions", slog.String("address", target), slog.Any("error", err))
+		return model.CheckResult{Status: UnHealthy, Error: err.Error(), ErrorClass: model.ErrorClassRequest}
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, checkTimeout(url))
+	defer cancel()
+
+	var spec model.TCPCheck
+	if url.TCP != nil {
+		spec = *url.TCP
+	}
+
+	start := time.Now()
+	failed := func(err error) model.CheckResult {
+		uc.logger.Warn("TCP check failed", slog.String("address", target), slog.Any("error", err))
+		return model.CheckResult{
+			Status:     UnHealthy,
+			Latency:    model.Duration(time.Since(start)),
+			Error:      err.Error(),
+			ErrorClass: classifyError(err),
+		}
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", target)
+	if err != nil {
+		return failed(err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if spec.Send != "" {
+		if _, err := io.WriteString(conn, spec.Send); err != nil {
+			return failed(err)
+		}
+	}
+
+	var banner []byte
+	if spec.Expect != "" || assertion.NeedsBody(url.Assertions) {
+		banner, err = readBanner(conn, spec.Expect)
+		if err != nil && (spec.Expect == "" || len(banner) == 0) {
+			return failed(err)
+		}
+	}
+	duration := time.Since(start)
+
+	result := model.CheckResult{Status: Healthy, Latency: model.Duration(duration)}
+	if spec.Expect != "" && !bytes.Contains(banner, []byte(spec.Expect)) {
+		result.FailedAssertions = append(result.FailedAssertions, fmt.Sprintf("response does not contain %q", spec.Expect))
+	}
+	result.FailedAssertions = append(result.FailedAssertions, assertions.Evaluate(assertion.Response{
+		Body:     banner,
+		Duration: duration,
+	})...)
+
+	if len(result.FailedAssertions) > 0 {
+		uc.logger.Warn("Assertions failed", slog.String("address", target), slog.Any("failures", result.FailedAssertions))
+		result.Status = UnHealthy
+		result.ErrorClass = model.ErrorClassAssertion
+	}
+	return result
+}
+
+// readBanner reads until expect shows up, the server closes the connection or
+// maxBannerBytes is reached. Without expect the first chunk is enough.
+func readBanner(conn net.Conn, expect string) ([]byte, error) {
+	var banner []byte
+	buf := make([]byte, 4096)
+	for len(banner) < maxBannerBytes {
+		n, err := conn.Read(buf)
+		banner = append(banner, buf[:n]...)
+		if expect == "" && len(banner) > 0 {
+			return banner, nil
+		}
+		if expect != "" && bytes.Contains(banner, []byte(expect)) {
+			return banner, nil
+		}
+		if errors.Is(err, io.EOF) {
+			return banner, nil
+		}
+		if err != nil {
+			return banner, err
+		}
+	}
+	return banner, nil
+}
@@ -0,0 +1,111 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// Test_URLChecker_pingTCP runs tcp monitors against a line-based echo server
+// that greets every connection with a banner
+func Test_URLChecker_pingTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.Write([]byte("+OK ready\r\n"))
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == "PING\r\n" {
+					conn.Write([]byte("+PONG\r\n"))
+				}
+			}()
+		}
+	}()
+
+	// A listener that never writes anything back
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer silent.Close()
+
+	addr := ln.Addr().String()
+	uc := &URLChecker{logger: slog.Default()}
+
+	tests := []struct {
+		name      string
+		url       model.URL
+		want      string
+		wantClass string
+	}{
+		{
+			name: "port open",
+			url:  model.URL{Type: model.MonitorTCP, Address: addr},
+			want: Healthy,
+		},
+		{
+			name: "banner matches",
+			url:  model.URL{Type: model.MonitorTCP, Address: addr, TCP: &model.TCPCheck{Expect: "+OK"}},
+			want: Healthy,
+		},
+		{
+			name: "payload and expected response",
+			url:  model.URL{Type: model.MonitorTCP, Address: addr, TCP: &model.TCPCheck{Send: "PING\r\n", Expect: "+PONG"}},
+			want: Healthy,
+		},
+		{
+			name: "regex assertion on banner",
+			url: model.URL{Type: model.MonitorTCP, Address: addr, Assertions: []model.Assertion{
+				{Type: model.AssertBodyRegex, Value: `^\+OK \w+`},
+			}},
+			want: Healthy,
+		},
+		{
+			name:      "unexpected response",
+			url:       model.URL{Type: model.MonitorTCP, Address: addr, TCP: &model.TCPCheck{Send: "QUIT\r\n", Expect: "+PONG"}},
+			want:      UnHealthy,
+			wantClass: model.ErrorClassAssertion,
+		},
+		{
+			name: "server never answers",
+			url: model.URL{Type: model.MonitorTCP, Address: silent.Addr().String(), Timeout: model.Duration(50 * time.Millisecond),
+				TCP: &model.TCPCheck{Expect: "+OK"}},
+			want:      UnHealthy,
+			wantClass: model.ErrorClassTimeout,
+		},
+		{
+			name:      "connection refused",
+			url:       model.URL{Type: model.MonitorTCP, Address: "127.0.0.1:1"},
+			want:      UnHealthy,
+			wantClass: model.ErrorClassRefused,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := uc.ping(context.Background(), tt.url)
+			if got.Status != tt.want {
+				t.Errorf("ping() status = %v, want %v (error %q, failures %v)", got.Status, tt.want, got.Error, got.FailedAssertions)
+			}
+			if got.ErrorClass != tt.wantClass {
+				t.Errorf("ping() error class = %q, want %q", got.ErrorClass, tt.wantClass)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package checker
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/metrics"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// phaseTimer records when the phases of an HTTP request start and end. With
+// redirects it restarts on every hop, so the timings describe the final request.
+type phaseTimer struct {
+	mu sync.Mutex // dialing may report from several goroutines
+
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	wroteRequest, firstByte   time.Time
+}
+
+// trace returns the hooks that feed the timer
+func (p *phaseTimer) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn:  func(string) { p.reset() },
+		DNSStart: func(httptrace.DNSStartInfo) { p.mark(&p.dnsStart, false) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { p.mark(&p.dnsDone, false) },
+		// Dual-stack dialing may race several connects; the first start and
+		// the winning done bound the phase
+		ConnectStart: func(_, _ string) { p.mark(&p.connectStart, true) },
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				p.mark(&p.connectDone, false)
+			}
+		},
+		TLSHandshakeStart:    func() { p.mark(&p.tlsStart, false) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { p.mark(&p.tlsDone, false) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { p.mark(&p.wroteRequest, false) },
+		GotFirstResponseByte: func() { p.mark(&p.firstByte, false) },
+	}
+}
+
+func (p *phaseTimer) mark(t *time.Time, keepFirst bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if keepFirst && !t.IsZero() {
+		return
+	}
+	*t = time.Now()
+}
+
+func (p *phaseTimer) reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.dnsStart, p.dnsDone = time.Time{}, time.Time{}
+	p.connectStart, p.connectDone = time.Time{}, time.Time{}
+	p.tlsStart, p.tlsDone = time.Time{}, time.Time{}
+	p.wroteRequest, p.firstByte = time.Time{}, time.Time{}
+}
+
+// timings returns the phase durations for a response read completely at end,
+// or nil when no response arrived
+func (p *phaseTimer) timings(end time.Time) *model.HTTPTimings {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.firstByte.IsZero() {
+		return nil
+	}
+	return &model.HTTPTimings{
+		DNSLookup:    between(p.dnsStart, p.dnsDone),
+		TCPConnect:   between(p.connectStart, p.connectDone),
+		TLSHandshake: between(p.tlsStart, p.tlsDone),
+		FirstByte:    between(p.wroteRequest, p.firstByte),
+		Transfer:     between(p.firstByte, end),
+	}
+}
+
+// between is the time from start to end, zero when either is missing
+func between(start, end time.Time) model.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return model.Duration(end.Sub(start))
+}
+
+// observeTimings feeds the per-phase histograms, skipping phases that didn't happen
+func observeTimings(t *model.HTTPTimings) {
+	if t == nil {
+		return
+	}
+	phases := []struct {
+		name string
+		d    model.Duration
+	}{
+		{"dns_lookup", t.DNSLookup},
+		{"tcp_connect", t.TCPConnect},
+		{"tls_handshake", t.TLSHandshake},
+		{"ttfb", t.FirstByte},
+		{"transfer", t.Transfer},
+	}
+	for _, p := range phases {
+		if p.d > 0 {
+			metrics.URLCheckPhaseDuration.WithLabelValues(p.name).Observe(p.d.Std().Seconds())
+		}
+	}
+}
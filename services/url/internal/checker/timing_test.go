@@ -0,0 +1,57 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// Test_sendHTTP_timings checks each phase is attributed where the time was spent
+func Test_sendHTTP_timings(t *testing.T) {
+	const (
+		think   = 60 * time.Millisecond
+		trickle = 40 * time.Millisecond
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(think)
+		w.Write([]byte("first half "))
+		w.(http.Flusher).Flush()
+		time.Sleep(trickle)
+		w.Write([]byte("second half"))
+	}))
+	defer server.Close()
+
+	req, err := newCheckRequest(context.Background(), "", server.URL, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := sendHTTP(server.Client(), req, false)
+	if err != nil {
+		t.Fatalf("sendHTTP() error = %v", err)
+	}
+	got := resp.timings
+	if got == nil {
+		t.Fatal("sendHTTP() timings = nil, want phases")
+	}
+
+	if got.DNSLookup != 0 {
+		t.Errorf("DNSLookup = %v, want 0 for an IP address", got.DNSLookup)
+	}
+	if got.TCPConnect <= 0 || got.TLSHandshake <= 0 {
+		t.Errorf("TCPConnect = %v, TLSHandshake = %v, want both set on a new connection", got.TCPConnect, got.TLSHandshake)
+	}
+	if got.FirstByte < model.Duration(think) {
+		t.Errorf("FirstByte = %v, want at least %v", got.FirstByte, think)
+	}
+	if got.Transfer < model.Duration(trickle) {
+		t.Errorf("Transfer = %v, want at least %v", got.Transfer, trickle)
+	}
+	sum := got.DNSLookup + got.TCPConnect + got.TLSHandshake + got.FirstByte + got.Transfer
+	if sum > model.Duration(resp.Duration) {
+		t.Errorf("phases add up to %v, more than the total %v", sum, resp.Duration)
+	}
+}
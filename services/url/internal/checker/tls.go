@@ -0,0 +1,75 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/assertion"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// pingTLS completes a TLS handshake with host:port and verifies the presented
+// chain. The check fails when the chain is untrusted, expired or issued for
+// another host.
+func (uc *URLChecker) pingTLS(parentCtx context.Context, url model.URL) model.CheckResult {
+	target := url.Address
+
+	assertions, err := assertion.Compile(url.Assertions)
+	if err != nil {
+		uc.logger.Warn("Invalid assertions", slog.String("address", target), slog.Any("error", err))
+		return model.CheckResult{Status: UnHealthy, Error: err.Error(), ErrorClass: model.ErrorClassRequest}
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, checkTimeout(url))
+	defer cancel()
+
+	serverName, _, _ := net.SplitHostPort(target)
+	if url.TLS != nil && url.TLS.ServerName != "" {
+		serverName = url.TLS.ServerName
+	}
+
+	// Verification happens below so the certificate is captured even when it's bad
+	dialer := tls.Dialer{Config: &tls.Config{ServerName: serverName, InsecureSkipVerify: true}}
+
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", target)
+	duration := time.Since(start)
+	if err != nil {
+		uc.logger.Warn("TLS handshake failed", slog.String("address", target), slog.Any("error", err))
+		return model.CheckResult{
+			Status:     UnHealthy,
+			Latency:    model.Duration(duration),
+			Error:      err.Error(),
+			ErrorClass: classifyError(err),
+		}
+	}
+	state := conn.(*tls.Conn).ConnectionState()
+	conn.Close()
+
+	result := model.CheckResult{
+		Status:  Healthy,
+		Latency: model.Duration(duration),
+		Cert:    verifyChain(state.PeerCertificates, serverName, uc.rootCAs, time.Now()),
+	}
+	if result.Cert == nil || !result.Cert.Valid {
+		result.Status = UnHealthy
+		result.ErrorClass = model.ErrorClassTLS
+		result.Error = "no certificate presented"
+		if result.Cert != nil {
+			result.Error = result.Cert.Error
+		}
+		uc.logger.Warn("Certificate invalid", slog.String("address", target), slog.String("error", result.Error))
+		return result
+	}
+
+	result.FailedAssertions = assertions.Evaluate(assertion.Response{Duration: duration})
+	if len(result.FailedAssertions) > 0 {
+		uc.logger.Warn("Assertions failed", slog.String("address", target), slog.Any("failures", result.FailedAssertions))
+		result.Status = UnHealthy
+		result.ErrorClass = model.ErrorClassAssertion
+	}
+	return result
+}
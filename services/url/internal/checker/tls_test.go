@@ -0,0 +1,147 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+func Test_URLChecker_pingTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// httptest certificates are issued for example.com and 127.0.0.1
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	addr := strings.TrimPrefix(server.URL, "https://")
+
+	tests := []struct {
+		name      string
+		roots     *x509.CertPool
+		url       model.URL
+		want      string
+		wantValid bool
+	}{
+		{
+			name:      "trusted chain matching the address",
+			roots:     roots,
+			url:       model.URL{Type: model.MonitorTLS, Address: addr},
+			want:      Healthy,
+			wantValid: true,
+		},
+		{
+			name:      "trusted chain matching the server name",
+			roots:     roots,
+			url:       model.URL{Type: model.MonitorTLS, Address: addr, TLS: &model.TLSCheck{ServerName: "example.com"}},
+			want:      Healthy,
+			wantValid: true,
+		},
+		{
+			name:  "hostname mismatch",
+			roots: roots,
+			url:   model.URL{Type: model.MonitorTLS, Address: addr, TLS: &model.TLSCheck{ServerName: "other.test"}},
+			want:  UnHealthy,
+		},
+		{
+			name:  "untrusted chain",
+			roots: x509.NewCertPool(),
+			url:   model.URL{Type: model.MonitorTLS, Address: addr},
+			want:  UnHealthy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &URLChecker{logger: slog.Default(), rootCAs: tt.roots}
+			got := uc.ping(context.Background(), tt.url)
+			if got.Status != tt.want {
+				t.Errorf("ping() status = %v, want %v (error %q)", got.Status, tt.want, got.Error)
+			}
+			if got.Cert == nil {
+				t.Fatal("ping() captured no certificate")
+			}
+			if got.Cert.Valid != tt.wantValid {
+				t.Errorf("cert valid = %v, want %v", got.Cert.Valid, tt.wantValid)
+			}
+			if !got.Cert.NotAfter.Equal(server.Certificate().NotAfter) {
+				t.Errorf("cert not_after = %v, want %v", got.Cert.NotAfter, server.Certificate().NotAfter)
+			}
+		})
+	}
+
+	// HTTPS monitors capture the certificate too, valid or not
+	uc := &URLChecker{logger: slog.Default(), httpClient: server.Client()}
+	if got := uc.ping(context.Background(), model.URL{Address: server.URL}); got.Cert == nil || !got.Cert.Valid {
+		t.Errorf("https ping cert = %+v, want a valid certificate", got.Cert)
+	}
+	uc.httpClient = &http.Client{}
+	got := uc.ping(context.Background(), model.URL{Address: server.URL})
+	if got.Status != UnHealthy || got.ErrorClass != model.ErrorClassTLS || got.Cert == nil || got.Cert.Valid {
+		t.Errorf("untrusted https ping = %s/%s cert %+v, want unhealthy tls with an invalid certificate", got.Status, got.ErrorClass, got.Cert)
+	}
+}
+
+func Test_certNotifications(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	url := model.URL{ID: "u1", Address: "example.com:443"}
+	thresholds := []int{30, 14, 7, 1}
+	days := func(d float64) time.Time { return now.Add(time.Duration(d * float64(24*time.Hour))) }
+
+	steps := []struct {
+		cert *model.CertInfo
+		want []string
+	}{
+		{cert: &model.CertInfo{Valid: true, NotAfter: days(40)}},
+		{cert: &model.CertInfo{Valid: true, NotAfter: days(29.5)}, want: []string{model.NotificationCertExpiring}},
+		{cert: &model.CertInfo{Valid: true, NotAfter: days(20)}},
+		{cert: nil}, // unreachable: nothing learned, nothing reset
+		{cert: &model.CertInfo{Valid: true, NotAfter: days(13)}, want: []string{model.NotificationCertExpiring}},
+		{cert: &model.CertInfo{Valid: true, NotAfter: days(0.5)}, want: []string{model.NotificationCertExpiring}},
+		{cert: &model.CertInfo{Valid: true, NotAfter: days(90)}}, // renewed
+		{cert: &model.CertInfo{Valid: true, NotAfter: days(25)}, want: []string{model.NotificationCertExpiring}},
+		{cert: &model.CertInfo{Valid: false, Error: "x509: certificate is valid for a.test, not b.test", NotAfter: days(90)},
+			want: []string{model.NotificationCertInvalid}},
+		{cert: &model.CertInfo{Valid: false, Error: "x509: certificate is valid for a.test, not b.test", NotAfter: days(90)}},
+		{cert: &model.CertInfo{Valid: true, NotAfter: days(90)}},
+		{cert: &model.CertInfo{Valid: false, Error: "x509: certificate has expired", NotAfter: days(-1)},
+			want: []string{model.NotificationCertInvalid}},
+	}
+
+	var state model.CheckState
+	for i, step := range steps {
+		var got []string
+		for _, n := range certNotifications(url, Healthy, &state, step.cert, thresholds, now) {
+			got = append(got, n.Type)
+		}
+		if strings.Join(got, ",") != strings.Join(step.want, ",") {
+			t.Errorf("step %d: notifications = %v, want %v", i, got, step.want)
+		}
+	}
+}
+
+// Test_certNotifications_skipVerify checks a monitor that turned verification
+// off hears about expiry but never about an unverified certificate
+func Test_certNotifications_skipVerify(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	url := model.URL{ID: "u1", Address: "https://self-signed.test", Transport: &model.TransportConfig{InsecureSkipVerify: true}}
+	cert := certFromResponse(&tls.ConnectionState{PeerCertificates: []*x509.Certificate{{NotAfter: now.Add(10 * 24 * time.Hour)}}})
+	if cert.Valid || cert.Error == "" {
+		t.Fatalf("certFromResponse() = valid %v error %q, want invalid with a reason", cert.Valid, cert.Error)
+	}
+
+	var state model.CheckState
+	var got []string
+	for _, n := range certNotifications(url, Healthy, &state, cert, []int{14}, now) {
+		got = append(got, n.Type)
+	}
+	if strings.Join(got, ",") != model.NotificationCertExpiring || state.CertInvalid {
+		t.Errorf("notifications = %v cert_invalid = %v, want only %s", got, state.CertInvalid, model.NotificationCertExpiring)
+	}
+}
@@ -0,0 +1,137 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+
+	"github.com/samims/hcaas/services/url/internal/assertion"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// pingTransaction runs the steps in order within the URL's timeout, stopping
+// at the first one that fails. Values extracted by a step are templated into
+// the steps after it.
+func (uc *URLChecker) pingTransaction(parentCtx context.Context, url model.URL) model.CheckResult {
+	if url.Transaction == nil || len(url.Transaction.Steps) == 0 {
+		return model.CheckResult{Status: UnHealthy, Error: "transaction has no steps", ErrorClass: model.ErrorClassRequest}
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, checkTimeout(url))
+	defer cancel()
+
+	base, err := uc.clientFor(ctx, url)
+	if err != nil {
+		uc.logger.Warn("Failed to set up transport", slog.String("address", url.Address), slog.Any("error", err))
+		return model.CheckResult{Status: UnHealthy, Error: err.Error(), ErrorClass: model.ErrorClassRequest}
+	}
+	// Steps share cookies the way a browser session would; cookiejar.New never fails without options
+	client := *base
+	client.Jar, _ = cookiejar.New(nil)
+
+	vars := map[string]string{}
+	result := model.CheckResult{Status: Healthy}
+	for i, step := range url.Transaction.Steps {
+		sr, class, cert := runStep(ctx, &client, step, vars)
+		result.Steps = append(result.Steps, sr)
+		result.StatusCode = sr.StatusCode
+		result.Latency += sr.Latency
+		// The first step targets the address, so its certificate stands for the monitor's
+		if i == 0 {
+			result.Cert = cert
+		}
+		if sr.Status == Healthy {
+			continue
+		}
+
+		uc.logger.Warn("Transaction step failed",
+			slog.String("address", url.Address),
+			slog.String("step", sr.Name),
+			slog.String("error", sr.Error),
+			slog.Any("failures", sr.FailedAssertions),
+		)
+		result.Status = UnHealthy
+		result.ErrorClass = class
+		result.FailedStep = sr.Name
+		if sr.Error != "" {
+			result.Error = fmt.Sprintf("step %q: %s", sr.Name, sr.Error)
+		}
+		for _, f := range sr.FailedAssertions {
+			result.FailedAssertions = append(result.FailedAssertions, fmt.Sprintf("step %q: %s", sr.Name, f))
+		}
+		break
+	}
+	return result
+}
+
+// runStep sends one step's request, evaluates its assertions and stores its
+// extractions in vars. It returns the error class alongside a failed result.
+func runStep(ctx context.Context, client *http.Client, step model.TransactionStep, vars map[string]string) (model.StepResult, string, *model.CertInfo) {
+	sr := model.StepResult{Name: step.Name, Status: UnHealthy}
+
+	req, err := renderStep(ctx, step, vars)
+	if err != nil {
+		sr.Error = err.Error()
+		return sr, model.ErrorClassRequest, nil
+	}
+	assertions, err := assertion.Compile(step.Assertions)
+	if err != nil {
+		sr.Error = err.Error()
+		return sr, model.ErrorClassRequest, nil
+	}
+	extractors := make([]*assertion.Extractor, 0, len(step.Extract))
+	for _, spec := range step.Extract {
+		e, err := assertion.CompileExtraction(spec)
+		if err != nil {
+			sr.Error = err.Error()
+			return sr, model.ErrorClassRequest, nil
+		}
+		extractors = append(extractors, e)
+	}
+
+	readBody := assertion.NeedsBody(step.Assertions) || assertion.ExtractionsNeedBody(step.Extract)
+	resp, err := sendHTTP(client, req, readBody)
+	sr.StatusCode = resp.StatusCode
+	sr.Latency = model.Duration(resp.Duration)
+	sr.Timings = resp.timings
+	if err != nil {
+		sr.Error = err.Error()
+		return sr, classifyError(err), resp.cert
+	}
+
+	if sr.FailedAssertions = assertions.Evaluate(resp.Response); len(sr.FailedAssertions) > 0 {
+		return sr, model.ErrorClassAssertion, resp.cert
+	}
+	for _, e := range extractors {
+		v, err := e.Extract(resp.Response)
+		if err != nil {
+			sr.Error = fmt.Sprintf("extract %s: %v", e.Name(), err)
+			return sr, model.ErrorClassAssertion, resp.cert
+		}
+		vars[e.Name()] = v
+	}
+
+	sr.Status = Healthy
+	return sr, "", resp.cert
+}
+
+// renderStep fills the extracted variables into the step and builds its request
+func renderStep(ctx context.Context, step model.TransactionStep, vars map[string]string) (*http.Request, error) {
+	target, err := assertion.Render(step.URL, vars)
+	if err != nil {
+		return nil, err
+	}
+	body, err := assertion.Render(step.Body, vars)
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string, len(step.Headers))
+	for name, value := range step.Headers {
+		if headers[name], err = assertion.Render(value, vars); err != nil {
+			return nil, err
+		}
+	}
+	return newCheckRequest(ctx, step.Method, target, headers, body)
+}
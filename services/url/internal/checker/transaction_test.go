@@ -0,0 +1,138 @@
+package checker
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// Test_pingTransaction runs a login flow whose token is templated into later steps.
+// Table Driven Test Pattern used
+func Test_pingTransaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			body, _ := io.ReadAll(r.Body)
+			if r.Method != http.MethodPost || string(body) != `{"user":"demo"}` {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1"})
+			w.Header().Set("X-Request-Id", "req-42")
+			w.Write([]byte(`{"data":{"token":"abc"}}`))
+		case "/orders/req-42":
+			cookie, err := r.Cookie("session")
+			if r.Header.Get("Authorization") != "Bearer abc" || err != nil || cookie.Value != "s1" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`order #7 ready`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	uc := &URLChecker{logger: slog.Default(), httpClient: server.Client()}
+
+	login := model.TransactionStep{
+		Name:   "login",
+		Method: http.MethodPost,
+		URL:    server.URL + "/login",
+		Body:   `{"user":"demo"}`,
+		Extract: []model.Extraction{
+			{Name: "token", From: model.ExtractJSONPath, Target: "data.token"},
+			{Name: "request_id", From: model.ExtractHeader, Target: "X-Request-Id"},
+		},
+	}
+	orders := model.TransactionStep{
+		Name:    "orders",
+		URL:     server.URL + "/orders/{{request_id}}",
+		Headers: map[string]string{"Authorization": "Bearer {{ token }}"},
+		Extract: []model.Extraction{{Name: "order", From: model.ExtractRegex, Target: `order #(\d+)`}},
+	}
+
+	tests := []struct {
+		name           string
+		steps          []model.TransactionStep
+		want           string
+		wantClass      string
+		wantFailedStep string
+		wantSteps      int
+	}{
+		{
+			name:      "all steps pass",
+			steps:     []model.TransactionStep{login, orders},
+			want:      Healthy,
+			wantSteps: 2,
+		},
+		{
+			name: "assertion fails in a later step",
+			steps: []model.TransactionStep{login, orders, {
+				Name:       "report",
+				URL:        server.URL + "/report",
+				Assertions: []model.Assertion{{Type: model.AssertStatusCode, Values: []string{"200"}}},
+			}},
+			want:           UnHealthy,
+			wantClass:      model.ErrorClassAssertion,
+			wantFailedStep: "report",
+			wantSteps:      3,
+		},
+		{
+			name: "missing extraction stops the run",
+			steps: []model.TransactionStep{{
+				Name:    "login",
+				Method:  http.MethodPost,
+				URL:     server.URL + "/login",
+				Body:    `{"user":"demo"}`,
+				Extract: []model.Extraction{{Name: "token", From: model.ExtractJSONPath, Target: "data.missing"}},
+			}, orders},
+			want:           UnHealthy,
+			wantClass:      model.ErrorClassAssertion,
+			wantFailedStep: "login",
+			wantSteps:      1,
+		},
+		{
+			name:           "connection refused",
+			steps:          []model.TransactionStep{{Name: "down", URL: "http://127.0.0.1:1/"}},
+			want:           UnHealthy,
+			wantClass:      model.ErrorClassRefused,
+			wantFailedStep: "down",
+			wantSteps:      1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := model.URL{
+				Type:        model.MonitorTransaction,
+				Address:     server.URL,
+				Transaction: &model.TransactionCheck{Steps: tt.steps},
+			}
+			got := uc.ping(context.Background(), url)
+			if got.Status != tt.want {
+				t.Errorf("ping() status = %v, want %v (%s)", got.Status, tt.want, failureReason(got))
+			}
+			if got.ErrorClass != tt.wantClass {
+				t.Errorf("ping() error class = %q, want %q", got.ErrorClass, tt.wantClass)
+			}
+			if got.FailedStep != tt.wantFailedStep {
+				t.Errorf("ping() failed step = %q, want %q", got.FailedStep, tt.wantFailedStep)
+			}
+			if len(got.Steps) != tt.wantSteps {
+				t.Fatalf("ping() ran %d steps, want %d", len(got.Steps), tt.wantSteps)
+			}
+			var total model.Duration
+			for _, s := range got.Steps {
+				total += s.Latency
+			}
+			if got.Latency != total {
+				t.Errorf("ping() latency = %v, want sum of steps %v", got.Latency, total)
+			}
+		})
+	}
+}
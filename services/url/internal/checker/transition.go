@@ -0,0 +1,69 @@
+package checker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// transitionNotification compares the URL's previous confirmed status with the new
+// one and builds a notification only when it went down, came back up or started
+// flapping. Repeated failures of a URL that is already down, and every change while
+// it is flapping, stay silent.
+func transitionNotification(url model.URL, prev, next monitorState, result model.CheckResult) (model.Notification, bool) {
+	if prev.status == next.status {
+		return model.Notification{}, false
+	}
+
+	notification := model.Notification{
+		UrlID:          url.ID,
+		PreviousStatus: prev.status,
+		Status:         "pending",
+		CreatedAt:      result.CheckedAt,
+	}
+
+	switch next.status {
+	case UnHealthy:
+		notification.Type = model.NotificationURLDown
+		notification.Message = fmt.Sprintf("URL is down: %s (%s)", url.Address, failureReason(result))
+		return notification, true
+
+	case Healthy:
+		if prev.status != UnHealthy && prev.status != model.StatusFlapping {
+			return model.Notification{}, false
+		}
+		notification.Type = model.NotificationURLRecovered
+		if prev.status == UnHealthy {
+			downtime := downtimeSince(prev.changedAt, result.CheckedAt)
+			notification.DowntimeSeconds = int64(downtime.Seconds())
+			notification.Message = fmt.Sprintf("URL recovered: %s (down for %s)", url.Address, downtime)
+		} else {
+			notification.Message = fmt.Sprintf("URL recovered: %s (stopped flapping)", url.Address)
+		}
+		return notification, true
+
+	case model.StatusFlapping:
+		notification.Type = model.NotificationURLFlapping
+		notification.Message = fmt.Sprintf("URL is flapping: %s (%d status changes within %s, alerts suppressed)",
+			url.Address, len(next.counters.RecentChanges), flapWindow(url))
+		return notification, true
+	}
+
+	return model.Notification{}, false
+}
+
+func flapWindow(url model.URL) time.Duration {
+	if url.FlapWindow <= 0 {
+		return model.DefaultFlapWindow.Std()
+	}
+	return url.FlapWindow.Std()
+}
+
+// downtimeSince is the time spent down, rounded for humans; zero if the start is unknown
+func downtimeSince(since, now time.Time) time.Duration {
+	if since.IsZero() || now.Before(since) {
+		return 0
+	}
+	return now.Sub(since).Round(time.Second)
+}
@@ -0,0 +1,209 @@
+package checker
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"sync"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+	"github.com/samims/hcaas/services/url/internal/service"
+)
+
+// clientIdleTTL is how long a client built for a transport config is kept
+// after its last use; monitors that were removed or reconfigured age out
+const clientIdleTTL = time.Hour
+
+// transportCache builds an HTTP client per distinct transport config and
+// shares it between the monitors that use it, so their connections are pooled
+type transportCache struct {
+	creds service.CredentialService
+
+	mu      sync.Mutex
+	clients map[string]*cachedClient // by configKey
+	pruned  time.Time
+}
+
+type cachedClient struct {
+	client   *http.Client
+	lastUsed time.Time
+}
+
+func newTransportCache(creds service.CredentialService) *transportCache {
+	return &transportCache{creds: creds, clients: make(map[string]*cachedClient)}
+}
+
+// clientFor returns the HTTP client a monitor's checks go through
+func (uc *URLChecker) clientFor(ctx context.Context, url model.URL) (*http.Client, error) {
+	if url.Transport == nil {
+		return uc.httpClient, nil
+	}
+	if uc.transports == nil {
+		return nil, errors.New("transport settings are not available to this checker")
+	}
+	return uc.transports.client(ctx, url.Transport)
+}
+
+// client returns the cached client for cfg, building it on first use
+func (c *transportCache) client(ctx context.Context, cfg *model.TransportConfig) (*http.Client, error) {
+	var cert *model.Credential
+	if cfg.ClientCertID != "" {
+		if c.creds == nil {
+			return nil, errors.New("credentials are not available to this checker")
+		}
+		var err error
+		if cert, err = c.creds.GetForCheck(ctx, cfg.ClientCertID); err != nil {
+			return nil, err
+		}
+	}
+	key, err := configKey(cfg, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.clients[key]; ok {
+		cached.lastUsed = now
+		return cached.client, nil
+	}
+
+	client, err := buildClient(cfg, cert)
+	if err != nil {
+		return nil, err
+	}
+	c.clients[key] = &cachedClient{client: client, lastUsed: now}
+	c.prune(now)
+	return client, nil
+}
+
+// prune closes and drops clients that haven't been used for clientIdleTTL.
+// Called with mu held.
+func (c *transportCache) prune(now time.Time) {
+	if now.Sub(c.pruned) < clientIdleTTL/4 {
+		return
+	}
+	c.pruned = now
+	for key, cached := range c.clients {
+		if now.Sub(cached.lastUsed) > clientIdleTTL {
+			cached.client.CloseIdleConnections()
+			delete(c.clients, key)
+		}
+	}
+}
+
+// configKey identifies a transport config. The client certificate's UpdatedAt
+// is part of it so a replaced certificate gets a fresh client.
+func configKey(cfg *model.TransportConfig, cert *model.Credential) (string, error) {
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(raw)
+	if cert != nil {
+		h.Write([]byte(cert.UpdatedAt.UTC().Format(time.RFC3339Nano)))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// buildClient turns a transport config into an HTTP client. Timeouts come
+// from each request's context, as with the default client.
+func buildClient(cfg *model.TransportConfig, cert *model.Credential) (*http.Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	if cfg.CABundle != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cfg.CABundle)) {
+			return nil, errors.New("ca_bundle contains no PEM certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cert != nil {
+		if cert.Type != model.CredentialClientCert {
+			return nil, fmt.Errorf("credential %s is not a client certificate", cert.ID)
+		}
+		pair, err := tls.X509KeyPair([]byte(cert.Certificate), []byte(cert.Secret))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if cfg.Proxy != "" {
+		proxyURL, err := neturl.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialContext(cfg),
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	return &http.Client{Transport: transport, CheckRedirect: redirectPolicy(cfg)}, nil
+}
+
+// dialContext applies the IP version, resolver and host overrides. They affect
+// whatever the checker connects to directly, which is the proxy when one is set.
+func dialContext(cfg *model.TransportConfig) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Resolver:  newResolver(cfg.Resolver),
+	}
+	overrides := cfg.HostOverrides
+	ipVersion := cfg.IPVersion
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		switch ipVersion {
+		case 4:
+			network = "tcp4"
+		case 6:
+			network = "tcp6"
+		}
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			if ip, ok := overrides[host]; ok {
+				addr = net.JoinHostPort(ip, port)
+			}
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
+
+// redirectPolicy stops at the first redirect when following is off, so its
+// status is what assertions see, and otherwise fails after MaxRedirects hops
+func redirectPolicy(cfg *model.TransportConfig) func(*http.Request, []*http.Request) error {
+	if cfg.FollowRedirects != nil && !*cfg.FollowRedirects {
+		return func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	limit := cfg.MaxRedirects
+	if limit <= 0 {
+		limit = model.DefaultMaxRedirects
+	}
+	return func(_ *http.Request, via []*http.Request) error {
+		if len(via) > limit {
+			return fmt.Errorf("stopped after %d redirects", limit)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,177 @@
+package checker
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// newClientCert returns a self-signed client certificate and its key as PEM
+func newClientCert(t *testing.T) (certPEM, keyPEM string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "probe"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+// Test_pingHTTP_transport checks CA bundles, client certificates, redirect
+// policy and host overrides are honoured per monitor.
+// Table Driven Test Pattern used
+func Test_pingHTTP_transport(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mtls":
+			if len(r.TLS.PeerCertificates) == 0 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		case "/redirect":
+			http.Redirect(w, r, "/hop?n=1", http.StatusFound)
+			return
+		case "/hop":
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "https://"))
+	certPEM, keyPEM := newClientCert(t)
+	creds := &memCredentials{byID: map[string]model.Credential{
+		"cert": {ID: "cert", Type: model.CredentialClientCert, Certificate: certPEM, Secret: keyPEM},
+	}}
+	// The default client doesn't trust the test server
+	uc := &URLChecker{logger: slog.Default(), httpClient: &http.Client{}, transports: newTransportCache(creds)}
+	noFollow := false
+
+	tests := []struct {
+		name      string
+		path      string
+		address   string
+		transport *model.TransportConfig
+		want      string
+		wantCode  int
+	}{
+		{
+			name: "untrusted without transport settings",
+			path: "/",
+			want: UnHealthy,
+		},
+		{
+			name:      "trusted through the CA bundle",
+			path:      "/",
+			transport: &model.TransportConfig{CABundle: caPEM},
+			want:      Healthy,
+			wantCode:  http.StatusOK,
+		},
+		{
+			name:      "insecure skip verify",
+			path:      "/",
+			transport: &model.TransportConfig{InsecureSkipVerify: true},
+			want:      Healthy,
+			wantCode:  http.StatusOK,
+		},
+		{
+			name:      "mTLS endpoint without a client certificate",
+			path:      "/mtls",
+			transport: &model.TransportConfig{CABundle: caPEM},
+			want:      UnHealthy,
+			wantCode:  http.StatusUnauthorized,
+		},
+		{
+			name:      "mTLS endpoint with a client certificate",
+			path:      "/mtls",
+			transport: &model.TransportConfig{CABundle: caPEM, ClientCertID: "cert"},
+			want:      Healthy,
+			wantCode:  http.StatusOK,
+		},
+		{
+			name:      "redirects followed",
+			path:      "/redirect",
+			transport: &model.TransportConfig{CABundle: caPEM},
+			want:      Healthy,
+			wantCode:  http.StatusOK,
+		},
+		{
+			name:      "redirects not followed",
+			path:      "/redirect",
+			transport: &model.TransportConfig{CABundle: caPEM, FollowRedirects: &noFollow},
+			want:      Healthy,
+			wantCode:  http.StatusFound,
+		},
+		{
+			name:      "too many redirects",
+			path:      "/redirect",
+			transport: &model.TransportConfig{CABundle: caPEM, MaxRedirects: 1},
+			want:      UnHealthy,
+		},
+		{
+			// httptest certificates are issued for example.com
+			name:    "host override",
+			address: "https://example.com:" + port + "/",
+			transport: &model.TransportConfig{
+				CABundle:      caPEM,
+				HostOverrides: map[string]string{"example.com": "127.0.0.1"},
+			},
+			want:     Healthy,
+			wantCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := tt.address
+			if address == "" {
+				address = server.URL + tt.path
+			}
+			got := uc.ping(context.Background(), model.URL{Address: address, Transport: tt.transport})
+			if got.Status != tt.want {
+				t.Errorf("ping() status = %v (%s), want %v", got.Status, got.Error, tt.want)
+			}
+			if got.StatusCode != tt.wantCode {
+				t.Errorf("ping() status code = %d, want %d", got.StatusCode, tt.wantCode)
+			}
+		})
+	}
+
+	// Monitors with the same settings share a client
+	if n := len(uc.transports.clients); n != 6 {
+		t.Errorf("cached clients = %d, want 6", n)
+	}
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/labels"
+)
+
+// Config holds the application settings loaded from environment variables.
+type Config struct {
+	KafkaConfig       KafkaConfig
+	CheckerConfig     CheckerConfig
+	CredentialsConfig CredentialsConfig
+}
+
+// KafkaConfig holds the notification producer settings.
+type KafkaConfig struct {
+	Brokers           []string
+	NotificationTopic string
+	// NotificationRoutes send the notifications of monitors whose labels match
+	// a selector to another topic; the first match wins, NotificationTopic
+	// takes the rest.
+	NotificationRoutes []NotificationRoute
+}
+
+// NotificationRoute is a label selector and the topic its notifications go to.
+type NotificationRoute struct {
+	Selector labels.Selector
+	Topic    string
+}
+
+// CredentialsConfig holds the key stored check credentials are encrypted with.
+type CredentialsConfig struct {
+	// Key is the 32-byte AES-256 master key wrapping each credential's data
+	// key. Without it, credentials can't be stored or used.
+	Key []byte
+}
+
+// CheckerConfig holds the background checker settings.
+type CheckerConfig struct {
+	// Enabled runs the checker inside the API process; turn it off when
+	// cmd/checker is deployed separately.
+	Enabled bool
+	// HTTPAddr is where the standalone checker serves health and metrics.
+	HTTPAddr string
+	// Workers is the number of checks that may run concurrently.
+	Workers int
+	// SyncInterval is how often URL additions and changes are pulled from storage.
+	SyncInterval time.Duration
+	// Jitter spreads each run by up to this fraction of the URL's interval.
+	Jitter float64
+
+	// InstanceID identifies this replica when leasing shards.
+	InstanceID string
+	// Shards is the number of shards URLs are hashed into; all replicas must agree.
+	Shards int
+	// LeaseTTL is how long a shard lease survives without being renewed.
+	LeaseTTL time.Duration
+	// HeartbeatInterval is how often leases are renewed and rebalanced.
+	HeartbeatInterval time.Duration
+
+	// CertExpiryDays are the days-before-expiry at which cert_expiring is sent.
+	CertExpiryDays []int
+}
+
+// LoadConfig reads environment variables and returns a Config or an error.
+func LoadConfig() (*Config, error) {
+	var err error
+	cfg := &Config{}
+
+	// Helper closures
+	getInt := func(key string, def int) (int, error) {
+		if v := os.Getenv(key); v != "" {
+			i, e := strconv.Atoi(v)
+			if e != nil {
+				return 0, fmt.Errorf("invalid %s: %w", key, e)
+			}
+			return i, nil
+		}
+		return def, nil
+	}
+
+	getFloat := func(key string, def float64) (float64, error) {
+		if v := os.Getenv(key); v != "" {
+			f, e := strconv.ParseFloat(v, 64)
+			if e != nil {
+				return 0, fmt.Errorf("invalid %s: %w", key, e)
+			}
+			return f, nil
+		}
+		return def, nil
+	}
+
+	getDuration := func(key string, def time.Duration) (time.Duration, error) {
+		if v := os.Getenv(key); v != "" {
+			d, e := time.ParseDuration(v)
+			if e != nil {
+				return 0, fmt.Errorf("invalid %s: %w", key, e)
+			}
+			return d, nil
+		}
+		return def, nil
+	}
+
+	getString := func(key, def string) string {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+		return def
+	}
+
+	getBool := func(key string, def bool) (bool, error) {
+		if v := os.Getenv(key); v != "" {
+			b, e := strconv.ParseBool(v)
+			if e != nil {
+				return false, fmt.Errorf("invalid %s: %w", key, e)
+			}
+			return b, nil
+		}
+		return def, nil
+	}
+
+	getIntList := func(key string, def []int) ([]int, error) {
+		v := os.Getenv(key)
+		if v == "" {
+			return def, nil
+		}
+		var out []int
+		for _, part := range strings.Split(v, ",") {
+			i, e := strconv.Atoi(strings.TrimSpace(part))
+			if e != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, e)
+			}
+			out = append(out, i)
+		}
+		return out, nil
+	}
+
+	// Kafka settings
+	brokers := getString("KAFKA_BROKERS", "")
+	cfg.KafkaConfig.NotificationTopic = getString("KAFKA_NOTIF_TOPIC", "")
+	if brokers == "" || cfg.KafkaConfig.NotificationTopic == "" {
+		return nil, fmt.Errorf("KAFKA_BROKERS and KAFKA_NOTIF_TOPIC must be set")
+	}
+	cfg.KafkaConfig.Brokers = strings.Split(brokers, ",")
+	if cfg.KafkaConfig.NotificationRoutes, err = parseRoutes(getString("KAFKA_NOTIF_ROUTES", "")); err != nil {
+		return nil, fmt.Errorf("invalid KAFKA_NOTIF_ROUTES: %w", err)
+	}
+
+	// Checker settings
+	if cfg.CheckerConfig.Enabled, err = getBool("CHECKER_ENABLED", true); err != nil {
+		return nil, err
+	}
+	cfg.CheckerConfig.HTTPAddr = getString("CHECKER_HTTP_ADDR", ":8083")
+	if cfg.CheckerConfig.Workers, err = getInt("CHECKER_WORKERS", 10); err != nil {
+		return nil, err
+	}
+	if cfg.CheckerConfig.Workers < 1 {
+		return nil, fmt.Errorf("CHECKER_WORKERS must be at least 1")
+	}
+	if cfg.CheckerConfig.SyncInterval, err = getDuration("CHECKER_SYNC_INTERVAL", 15*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.CheckerConfig.Jitter, err = getFloat("CHECKER_JITTER", 0.1); err != nil {
+		return nil, err
+	}
+	if cfg.CheckerConfig.Jitter < 0 || cfg.CheckerConfig.Jitter > 0.5 {
+		return nil, fmt.Errorf("CHECKER_JITTER must be between 0 and 0.5")
+	}
+
+	hostname, _ := os.Hostname()
+	cfg.CheckerConfig.InstanceID = getString("CHECKER_INSTANCE_ID", fmt.Sprintf("%s-%d", hostname, os.Getpid()))
+	if cfg.CheckerConfig.Shards, err = getInt("CHECKER_SHARDS", 64); err != nil {
+		return nil, err
+	}
+	if cfg.CheckerConfig.Shards < 1 {
+		return nil, fmt.Errorf("CHECKER_SHARDS must be at least 1")
+	}
+	if cfg.CheckerConfig.LeaseTTL, err = getDuration("CHECKER_LEASE_TTL", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.CheckerConfig.HeartbeatInterval, err = getDuration("CHECKER_HEARTBEAT_INTERVAL", 10*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.CheckerConfig.HeartbeatInterval >= cfg.CheckerConfig.LeaseTTL {
+		return nil, fmt.Errorf("CHECKER_HEARTBEAT_INTERVAL must be shorter than CHECKER_LEASE_TTL")
+	}
+
+	if cfg.CheckerConfig.CertExpiryDays, err = getIntList("CHECKER_CERT_EXPIRY_DAYS", []int{30, 14, 7, 1}); err != nil {
+		return nil, err
+	}
+	for _, days := range cfg.CheckerConfig.CertExpiryDays {
+		if days < 1 {
+			return nil, fmt.Errorf("CHECKER_CERT_EXPIRY_DAYS must be positive day counts")
+		}
+	}
+
+	// Credentials settings
+	if key := getString("CREDENTIALS_KEY", ""); key != "" {
+		if cfg.CredentialsConfig.Key, err = base64.StdEncoding.DecodeString(key); err != nil {
+			return nil, fmt.Errorf("invalid CREDENTIALS_KEY: %w", err)
+		}
+		if len(cfg.CredentialsConfig.Key) != 32 {
+			return nil, fmt.Errorf("CREDENTIALS_KEY must be 32 bytes, base64-encoded")
+		}
+	}
+
+	return cfg, nil
+}
+
+// parseRoutes reads semicolon-separated "selector=>topic" routes, such as
+// "team=payments,env=prod=>payments-alerts;env=staging=>staging-alerts"
+func parseRoutes(v string) ([]NotificationRoute, error) {
+	var routes []NotificationRoute
+	for _, part := range strings.Split(v, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		i := strings.LastIndex(part, "=>")
+		if i < 0 {
+			return nil, fmt.Errorf("route %q is not selector=>topic", part)
+		}
+		selector, err := labels.Parse(part[:i])
+		if err != nil {
+			return nil, err
+		}
+		if len(selector) == 0 {
+			return nil, fmt.Errorf("route %q has an empty selector", part)
+		}
+		topic := strings.TrimSpace(part[i+2:])
+		if topic == "" {
+			return nil, fmt.Errorf("route %q has no topic", part)
+		}
+		routes = append(routes, NotificationRoute{Selector: selector, Topic: topic})
+	}
+	return routes, nil
+}
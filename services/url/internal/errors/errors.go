@@ -8,18 +8,33 @@ import (
 var (
 	ErrNotFound = errors.New("not found")
 	ErrConflict = errors.New("conflict")
+	ErrInvalid  = errors.New("invalid input")
 )
 
+// kindError keeps the formatted message while letting errors.Is match
+// the sentinel it was created from.
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func (e *kindError) Error() string { return e.msg }
+func (e *kindError) Unwrap() error { return e.kind }
+
 func NewInternal(format string, a ...interface{}) error {
 	return fmt.Errorf("INTERNAL: "+format, a...)
 }
 
 func NewNotFound(format string, a ...interface{}) error {
-	return fmt.Errorf("NOT FOUND: "+format, a...)
+	return &kindError{kind: ErrNotFound, msg: fmt.Sprintf("NOT FOUND: "+format, a...)}
 }
 
 func NewConflict(format string, a ...interface{}) error {
-	return fmt.Errorf("CONFLICT: "+format, a...)
+	return &kindError{kind: ErrConflict, msg: fmt.Sprintf("CONFLICT: "+format, a...)}
+}
+
+func NewInvalid(format string, a ...interface{}) error {
+	return &kindError{kind: ErrInvalid, msg: fmt.Sprintf("INVALID: "+format, a...)}
 }
 
 func IsNotFound(err error) bool {
@@ -30,6 +45,10 @@ func IsConflict(err error) bool {
 	return errors.Is(err, ErrConflict)
 }
 
+func IsInvalid(err error) bool {
+	return errors.Is(err, ErrInvalid)
+}
+
 func IsInternal(err error) bool {
-	return err != nil && !IsNotFound(err) && !IsConflict(err)
+	return err != nil && !IsNotFound(err) && !IsConflict(err) && !IsInvalid(err)
 }
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"cmp"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/samims/hcaas/services/url/internal/bulk"
+	"github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// maxImportBytes caps the size of an import body
+const maxImportBytes = 5 << 20
+
+// Import creates or updates monitors from a JSON, YAML or CSV list.
+// Query params: format (json, yaml or csv; by default taken from the
+// Content-Type), dry_run. A rejected import answers 400 with the report of
+// every row.
+func (h *URLHandler) Import(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	format, err := bulk.FormatFor(params.Get("format"), r.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var dryRun bool
+	if v := params.Get("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "invalid dry_run", http.StatusBadRequest)
+			return
+		}
+	}
+
+	rows, err := bulk.Decode(format, http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		h.logger.Warn("Invalid request body for Import", slog.String("format", format), slog.Any("error", err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.svc.Import(r.Context(), rows, dryRun)
+	if err != nil {
+		if errors.IsInvalid(err) && report != nil {
+			h.logger.Warn("Import rejected", slog.Int("invalid", report.Invalid))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(report)
+			return
+		}
+		writeError(w, h.logger, "Import", err)
+		return
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// Export writes the user's monitors in a format Import reads back.
+// Query params: format (json, yaml or csv; by default taken from the Accept header).
+func (h *URLHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format, err := bulk.FormatFor(r.URL.Query().Get("format"), r.Header.Get("Accept"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	urls, err := h.svc.GetAllByUserID(r.Context())
+	if err != nil {
+		writeError(w, h.logger, "Export", err)
+		return
+	}
+	slices.SortFunc(urls, func(a, b model.URL) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	w.Header().Set("Content-Type", bulk.ContentTypes[format])
+	w.Header().Set("Content-Disposition", `attachment; filename="monitors.`+format+`"`)
+	if err := bulk.Encode(format, w, urls); err != nil {
+		h.logger.Error("Export failed", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// GetChecks lists the check history of a URL.
+// Query params: from, to (RFC3339), limit, cursor.
+func (h *URLHandler) GetChecks(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	params := r.URL.Query()
+
+	var (
+		q   model.CheckQuery
+		err error
+	)
+	if q.From, err = parseTimeParam(params.Get("from")); err != nil {
+		http.Error(w, "invalid from: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if q.To, err = parseTimeParam(params.Get("to")); err != nil {
+		http.Error(w, "invalid to: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if v := params.Get("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil || q.Limit < 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	q.Cursor = params.Get("cursor")
+
+	page, err := h.svc.GetChecks(r.Context(), id, q)
+	if err != nil {
+		writeError(w, h.logger.With("id", id), "GetChecks", err)
+		return
+	}
+	json.NewEncoder(w).Encode(page)
+}
+
+// parseTimeParam parses an optional RFC3339 query parameter
+func parseTimeParam(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
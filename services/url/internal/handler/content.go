@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetContent shows a content monitor's baseline, the pending change and the diff between them
+func (h *URLHandler) GetContent(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	review, err := h.svc.GetContent(r.Context(), id)
+	if err != nil {
+		writeError(w, h.logger.With("id", id), "GetContent", err)
+		return
+	}
+	json.NewEncoder(w).Encode(review)
+}
+
+// AcceptContent makes the pending content change the new baseline
+func (h *URLHandler) AcceptContent(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	baseline, err := h.svc.AcceptContent(r.Context(), id)
+	if err != nil {
+		writeError(w, h.logger.With("id", id), "AcceptContent", err)
+		return
+	}
+	json.NewEncoder(w).Encode(baseline)
+}
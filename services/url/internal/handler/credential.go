@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+	"github.com/samims/hcaas/services/url/internal/service"
+)
+
+type CredentialHandler struct {
+	svc    service.CredentialService
+	logger *slog.Logger
+}
+
+func NewCredentialHandler(s service.CredentialService, logger *slog.Logger) *CredentialHandler {
+	return &CredentialHandler{svc: s, logger: logger}
+}
+
+// Create stores a credential. The response never includes the secret.
+func (h *CredentialHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var c model.Credential
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		h.logger.Warn("Invalid request body for Create credential")
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.svc.Create(r.Context(), c)
+	if err != nil {
+		writeError(w, h.logger, "CreateCredential", err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *CredentialHandler) List(w http.ResponseWriter, r *http.Request) {
+	credentials, err := h.svc.List(r.Context())
+	if err != nil {
+		writeError(w, h.logger, "ListCredentials", err)
+		return
+	}
+	json.NewEncoder(w).Encode(credentials)
+}
+
+func (h *CredentialHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		writeError(w, h.logger.With("id", id), "DeleteCredential", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
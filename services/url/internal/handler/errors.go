@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/samims/hcaas/services/url/internal/errors"
+)
+
+// writeError maps a service error onto the matching HTTP status code
+func writeError(w http.ResponseWriter, logger *slog.Logger, op string, err error) {
+	switch {
+	case errors.IsInvalid(err):
+		logger.Warn(op+" rejected", slog.Any("error", err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.IsNotFound(err):
+		logger.Warn(op+" not found", slog.Any("error", err))
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.IsConflict(err):
+		logger.Warn(op+" conflict", slog.Any("error", err))
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		logger.Error(op+" failed", slog.Any("error", err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ListGroups returns the user's groups with their aggregate status
+func (h *URLHandler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.svc.ListGroups(r.Context())
+	if err != nil {
+		writeError(w, h.logger, "ListGroups", err)
+		return
+	}
+	json.NewEncoder(w).Encode(groups)
+}
+
+// GetGroup returns one group with its aggregate status. Its monitors are
+// listed by GET /urls/me?group={name}.
+func (h *URLHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	group, err := h.svc.GetGroup(r.Context(), name)
+	if err != nil {
+		writeError(w, h.logger.With("group", name), "GetGroup", err)
+		return
+	}
+	json.NewEncoder(w).Encode(group)
+}
+
+// PauseGroup stops checks of every monitor in a group until it is resumed
+func (h *URLHandler) PauseGroup(w http.ResponseWriter, r *http.Request) {
+	h.setGroupPaused(w, r, true)
+}
+
+// ResumeGroup restarts checks of every monitor in a group
+func (h *URLHandler) ResumeGroup(w http.ResponseWriter, r *http.Request) {
+	h.setGroupPaused(w, r, false)
+}
+
+func (h *URLHandler) setGroupPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	name := chi.URLParam(r, "name")
+
+	group, err := h.svc.SetGroupPaused(r.Context(), name, paused)
+	if err != nil {
+		writeError(w, h.logger.With("group", name, "paused", paused), "SetGroupPaused", err)
+		return
+	}
+	json.NewEncoder(w).Encode(group)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/samims/hcaas/services/url/internal/checker"
+	"github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// maxPingBody caps how much job output a ping may carry
+const maxPingBody = 10 << 10
+
+type HeartbeatHandler struct {
+	receiver checker.HeartbeatReceiver
+	logger   *slog.Logger
+}
+
+func NewHeartbeatHandler(receiver checker.HeartbeatReceiver, logger *slog.Logger) *HeartbeatHandler {
+	return &HeartbeatHandler{receiver: receiver, logger: logger}
+}
+
+// Ping handles POST /heartbeat/{token}
+func (h *HeartbeatHandler) Ping(w http.ResponseWriter, r *http.Request) {
+	h.receive(w, r, model.HeartbeatPing{Kind: model.HeartbeatSuccess})
+}
+
+// Start handles POST /heartbeat/{token}/start
+func (h *HeartbeatHandler) Start(w http.ResponseWriter, r *http.Request) {
+	h.receive(w, r, model.HeartbeatPing{Kind: model.HeartbeatStart})
+}
+
+// Fail handles POST /heartbeat/{token}/fail
+func (h *HeartbeatHandler) Fail(w http.ResponseWriter, r *http.Request) {
+	h.receive(w, r, model.HeartbeatPing{Kind: model.HeartbeatFail})
+}
+
+// ExitCode handles POST /heartbeat/{token}/{code}; zero counts as success
+func (h *HeartbeatHandler) ExitCode(w http.ResponseWriter, r *http.Request) {
+	code, err := strconv.Atoi(chi.URLParam(r, "code"))
+	if err != nil || code < 0 || code > 255 {
+		http.Error(w, "exit code must be between 0 and 255", http.StatusBadRequest)
+		return
+	}
+	h.receive(w, r, model.HeartbeatPing{Kind: model.HeartbeatExitCode, ExitCode: code})
+}
+
+func (h *HeartbeatHandler) receive(w http.ResponseWriter, r *http.Request, ping model.HeartbeatPing) {
+	token := chi.URLParam(r, "token")
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPingBody))
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+	ping.Body = string(body)
+
+	if err := h.receiver.Receive(r.Context(), token, ping); err != nil {
+		if errors.IsNotFound(err) {
+			http.Error(w, "heartbeat not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Heartbeat ping failed", slog.String("kind", ping.Kind), slog.Any("error", err))
+		http.Error(w, "failed to record ping", http.StatusInternalServerError)
+		return
+	}
+	w.Write([]byte("OK"))
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/samims/hcaas/services/url/internal/bulk"
+	"github.com/samims/hcaas/services/url/internal/errors"
+)
+
+// Sync reconciles the user's managed monitors, or a group's, with a declarative
+// document in JSON or YAML. Query params: format (json or yaml; by default taken
+// from the Content-Type), dry_run to only get the plan. A rejected sync answers
+// 400 with the plan of every monitor.
+func (h *URLHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	format, err := bulk.FormatFor(params.Get("format"), r.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var dryRun bool
+	if v := params.Get("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "invalid dry_run", http.StatusBadRequest)
+			return
+		}
+	}
+
+	doc, err := bulk.DecodeDocument(format, http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		h.logger.Warn("Invalid request body for Sync", slog.String("format", format), slog.Any("error", err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plan, err := h.svc.Sync(r.Context(), doc, dryRun)
+	if err != nil {
+		if errors.IsInvalid(err) && plan != nil {
+			h.logger.Warn("Sync rejected", slog.Int("invalid", plan.Invalid))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(plan)
+			return
+		}
+		writeError(w, h.logger, "Sync", err)
+		return
+	}
+	json.NewEncoder(w).Encode(plan)
+}
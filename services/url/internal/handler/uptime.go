@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// defaultReportWindow is used when neither window nor from/to is given
+const defaultReportWindow = 24 * time.Hour
+
+// GetUptime reports availability of a single URL.
+// Query params: window (24h, 7d, 30d, ...) or from/to (RFC3339).
+func (h *URLHandler) GetUptime(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	from, to, err := parseReportWindow(r.URL.Query(), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.svc.GetUptime(r.Context(), id, from, to)
+	if err != nil {
+		writeError(w, h.logger.With("id", id), "GetUptime", err)
+		return
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetUserReport reports availability of every URL the caller owns.
+// Accepts the same query params as GetUptime.
+func (h *URLHandler) GetUserReport(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseReportWindow(r.URL.Query(), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.svc.GetUserReport(r.Context(), from, to)
+	if err != nil {
+		writeError(w, h.logger, "GetUserReport", err)
+		return
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// parseReportWindow resolves the report range. An explicit from/to wins over window;
+// a missing to means now.
+func parseReportWindow(params url.Values, now time.Time) (time.Time, time.Time, error) {
+	from, err := parseTimeParam(params.Get("from"))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid from: %w", err)
+	}
+	to, err := parseTimeParam(params.Get("to"))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid to: %w", err)
+	}
+	if to.IsZero() {
+		to = now
+	}
+	if !from.IsZero() {
+		return from, to, nil
+	}
+
+	window := defaultReportWindow
+	if v := params.Get("window"); v != "" {
+		if window, err = parseWindow(v); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	return to.Add(-window), to, nil
+}
+
+// parseWindow accepts Go durations plus a day suffix ("7d")
+func parseWindow(v string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid window %q", v)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid window %q", v)
+	}
+	return d, nil
+}
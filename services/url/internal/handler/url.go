@@ -4,10 +4,15 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/samims/hcaas/services/url/internal/bulk"
 	"github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/labels"
 	"github.com/samims/hcaas/services/url/internal/model"
 	"github.com/samims/hcaas/services/url/internal/service"
 )
@@ -21,24 +26,84 @@ func NewURLHandler(s service.URLService, logger *slog.Logger) *URLHandler {
 	return &URLHandler{svc: s, logger: logger}
 }
 
+// GetAll returns all of the user's monitors. Monitors hold request headers,
+// bodies and other secrets, so no endpoint lists other users' monitors.
 func (h *URLHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	urls, err := h.svc.GetAll(r.Context())
+	urls, err := h.svc.GetAllByUserID(r.Context())
 	if err != nil {
-		h.logger.Error("GetAll failed", slog.Any("error", err))
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, h.logger, "GetAll", err)
 		return
 	}
+	if urls == nil {
+		urls = []model.URL{}
+	}
 	json.NewEncoder(w).Encode(urls)
 }
 
-func (h *URLHandler) GetAllByUserID(w http.ResponseWriter, r *http.Request) {
-	urls, err := h.svc.GetAllByUserID(r.Context())
+// List pages through the user's monitors.
+// Query params: status, type, tag (repeatable or comma-separated), group,
+// selector (a label selector such as env=prod,team!=search), paused,
+// checked_from, checked_to (RFC3339), q, sort (prefix with - for descending),
+// limit, cursor.
+func (h *URLHandler) List(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	q := model.URLQuery{
+		Status: listParam(params, "status"),
+		Type:   listParam(params, "type"),
+		Tags:   listParam(params, "tag"),
+		Group:  strings.TrimSpace(params.Get("group")),
+		Search: strings.TrimSpace(params.Get("q")),
+		Cursor: params.Get("cursor"),
+	}
+	var err error
+	if q.Selector, err = labels.Parse(params.Get("selector")); err != nil {
+		http.Error(w, "invalid selector: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if v := params.Get("paused"); v != "" {
+		paused, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "invalid paused", http.StatusBadRequest)
+			return
+		}
+		q.Paused = &paused
+	}
+	if q.CheckedFrom, err = parseTimeParam(params.Get("checked_from")); err != nil {
+		http.Error(w, "invalid checked_from: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if q.CheckedTo, err = parseTimeParam(params.Get("checked_to")); err != nil {
+		http.Error(w, "invalid checked_to: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.Sort, q.Desc = strings.CutPrefix(params.Get("sort"), "-")
+	if v := params.Get("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil || q.Limit < 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.svc.List(r.Context(), q)
 	if err != nil {
-		h.logger.Error("GetAllByUSerID failed", slog.Any("error", err))
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, h.logger, "List", err)
+		return
 	}
+	json.NewEncoder(w).Encode(page)
+}
 
-	json.NewEncoder(w).Encode(urls)
+// listParam collects a query param given repeatedly, comma-separated, or both
+func listParam(params url.Values, name string) []string {
+	var values []string
+	for _, v := range params[name] {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+	return values
 }
 
 func (h *URLHandler) GetByID(w http.ResponseWriter, r *http.Request) {
@@ -66,17 +131,23 @@ func (h *URLHandler) Add(w http.ResponseWriter, r *http.Request) {
 	}
 	url.Status = model.StatusUnknown
 
-	if err := h.svc.Add(r.Context(), url); err != nil {
-		if errors.IsInternal(err) {
-			h.logger.Warn("Duplicate or invalid Add", "url", url, "error", err)
+	created, err := h.svc.Add(r.Context(), url)
+	if err != nil {
+		switch {
+		case errors.IsInvalid(err):
+			h.logger.Warn("Invalid Add", "url", url, "error", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.IsConflict(err):
+			h.logger.Warn("Duplicate Add", "url", url, "error", err)
 			http.Error(w, err.Error(), http.StatusConflict)
-		} else {
+		default:
 			h.logger.Error("Add failed", "url", url, "error", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
 }
 
 func (h *URLHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
@@ -92,14 +163,137 @@ func (h *URLHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.UpdateStatus(r.Context(), id, body.Status); err != nil {
-		if errors.IsNotFound(err) {
-			h.logger.Warn("URL not found for update", "id", id)
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			h.logger.Error("UpdateStatus failed", "id", id, "error", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, h.logger.With("id", id), "UpdateStatus", err)
+		return
+	}
+	url, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, h.logger.With("id", id), "UpdateStatus", err)
+		return
+	}
+	json.NewEncoder(w).Encode(url)
+}
+
+// Update replaces a monitor's check spec
+func (h *URLHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var url model.URL
+	if err := json.NewDecoder(r.Body).Decode(&url); err != nil {
+		h.logger.Warn("Invalid request body for Update", "id", id)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.svc.Update(r.Context(), id, url)
+	if err != nil {
+		writeError(w, h.logger.With("id", id), "Update", err)
+		return
+	}
+	json.NewEncoder(w).Encode(updated)
+}
+
+// typeFields are the parts of a spec that only mean something for some monitor
+// types; a patch that changes the type drops those it doesn't set again
+var typeFields = []string{
+	"method", "headers", "body", "tcp", "dns", "tls", "grpc", "heartbeat", "transaction",
+	"credential_id", "transport", "content", "assertions",
+}
+
+// Patch applies a JSON merge patch (RFC 7396) to the stored spec: fields in the
+// body replace the stored ones, objects such as headers and labels merge key by
+// key, and null removes a field or key.
+func (h *URLHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var patch map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+		h.logger.Warn("Invalid request body for Patch", "id", id)
+		http.Error(w, "request body must be a JSON object", http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, h.logger.With("id", id), "Patch", err)
+		return
+	}
+	spec, err := bulk.Spec(*existing)
+	if err != nil {
+		writeError(w, h.logger.With("id", id), "Patch", errors.NewInternal("failed to read monitor: %v", err))
+		return
+	}
+	if newType, ok := patch["type"]; ok {
+		typ, _ := newType.(string)
+		if typ == "" {
+			typ = model.MonitorHTTP
+		}
+		if !strings.EqualFold(strings.TrimSpace(typ), existing.Type) {
+			for _, field := range typeFields {
+				delete(spec, field)
+			}
 		}
+	}
+
+	data, err := json.Marshal(bulk.MergePatch(spec, patch))
+	if err != nil {
+		writeError(w, h.logger.With("id", id), "Patch", errors.NewInternal("failed to apply patch: %v", err))
+		return
+	}
+	var url model.URL
+	if err := json.Unmarshal(data, &url); err != nil {
+		writeError(w, h.logger.With("id", id), "Patch", errors.NewInvalid("invalid patch: %v", err))
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+
+	updated, err := h.svc.Update(r.Context(), id, url)
+	if err != nil {
+		writeError(w, h.logger.With("id", id), "Patch", err)
+		return
+	}
+	json.NewEncoder(w).Encode(updated)
+}
+
+// Delete soft-deletes a monitor; Restore brings it back
+func (h *URLHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		writeError(w, h.logger.With("id", id), "Delete", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Restore undeletes a soft-deleted monitor
+func (h *URLHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	url, err := h.svc.Restore(r.Context(), id)
+	if err != nil {
+		writeError(w, h.logger.With("id", id), "Restore", err)
+		return
+	}
+	json.NewEncoder(w).Encode(url)
+}
+
+// Pause stops checks of a monitor until it is resumed
+func (h *URLHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.setPaused(w, r, true)
+}
+
+// Resume restarts checks of a paused monitor
+func (h *URLHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.setPaused(w, r, false)
+}
+
+func (h *URLHandler) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	id := chi.URLParam(r, "id")
+
+	url, err := h.svc.SetPaused(r.Context(), id, paused)
+	if err != nil {
+		writeError(w, h.logger.With("id", id, "paused", paused), "SetPaused", err)
+		return
+	}
+	json.NewEncoder(w).Encode(url)
 }
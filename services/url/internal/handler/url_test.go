@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+	"github.com/samims/hcaas/services/url/internal/service"
+)
+
+// patchService serves one stored monitor and keeps the spec Update was given;
+// methods the tests don't use panic through the embedded nil interface
+type patchService struct {
+	service.URLService
+	stored  model.URL
+	updated *model.URL
+}
+
+func (s *patchService) GetByID(context.Context, string) (*model.URL, error) {
+	url := s.stored
+	return &url, nil
+}
+
+func (s *patchService) Update(_ context.Context, id string, url model.URL) (*model.URL, error) {
+	url.ID = id
+	s.updated = &url
+	return &url, nil
+}
+
+// Test_URLHandler_Patch tests PATCH /urls/{id} as a JSON merge patch.
+// Table Driven Test Pattern used
+func Test_URLHandler_Patch(t *testing.T) {
+	stored := model.URL{
+		ID:       "u1",
+		Name:     "checkout",
+		Tags:     []string{"shop", "prod"},
+		Labels:   map[string]string{"env": "prod", "team": "payments"},
+		Address:  "https://shop.example.com/health",
+		Type:     model.MonitorHTTP,
+		Method:   "POST",
+		Headers:  map[string]string{"X-Api-Key": "secret", "Accept": "application/json"},
+		Body:     `{"ping":true}`,
+		Interval: model.Duration(time.Minute),
+		Status:   model.StatusUP,
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		check    func(t *testing.T, got model.URL)
+	}{
+		{
+			name:     "null removes a header",
+			body:     `{"headers": {"X-Api-Key": null}}`,
+			wantCode: http.StatusOK,
+			check: func(t *testing.T, got model.URL) {
+				if want := map[string]string{"Accept": "application/json"}; !reflect.DeepEqual(got.Headers, want) {
+					t.Errorf("Headers = %v, want %v", got.Headers, want)
+				}
+			},
+		},
+		{
+			name:     "labels merge key by key",
+			body:     `{"labels": {"team": null, "tier": "1"}}`,
+			wantCode: http.StatusOK,
+			check: func(t *testing.T, got model.URL) {
+				if want := map[string]string{"env": "prod", "tier": "1"}; !reflect.DeepEqual(got.Labels, want) {
+					t.Errorf("Labels = %v, want %v", got.Labels, want)
+				}
+			},
+		},
+		{
+			name:     "null removes a field, the rest is kept",
+			body:     `{"body": null, "interval": "30s"}`,
+			wantCode: http.StatusOK,
+			check: func(t *testing.T, got model.URL) {
+				if got.Body != "" || got.Interval.Std() != 30*time.Second || got.Method != "POST" || got.Name != "checkout" {
+					t.Errorf("got body %q interval %v method %q name %q", got.Body, got.Interval.Std(), got.Method, got.Name)
+				}
+			},
+		},
+		{
+			name:     "arrays are replaced",
+			body:     `{"tags": ["shop"]}`,
+			wantCode: http.StatusOK,
+			check: func(t *testing.T, got model.URL) {
+				if !reflect.DeepEqual(got.Tags, []string{"shop"}) {
+					t.Errorf("Tags = %v, want [shop]", got.Tags)
+				}
+			},
+		},
+		{
+			name:     "changing the type drops the old type's fields",
+			body:     `{"type": "tcp", "address": "shop.example.com:443"}`,
+			wantCode: http.StatusOK,
+			check: func(t *testing.T, got model.URL) {
+				if got.Method != "" || got.Headers != nil || got.Body != "" || got.Name != "checkout" {
+					t.Errorf("got method %q headers %v body %q name %q", got.Method, got.Headers, got.Body, got.Name)
+				}
+			},
+		},
+		{
+			name:     "not an object",
+			body:     `["interval"]`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "wrong value type",
+			body:     `{"failure_threshold": "three"}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &patchService{stored: stored}
+			h := NewURLHandler(svc, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", stored.ID)
+			req := httptest.NewRequest(http.MethodPatch, "/urls/"+stored.ID, strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rec := httptest.NewRecorder()
+
+			h.Patch(rec, req)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			if tt.check != nil {
+				tt.check(t, *svc.updated)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/IBM/sarama"
 
+	"github.com/samims/hcaas/services/url/internal/config"
 	"github.com/samims/hcaas/services/url/internal/model"
 )
 
@@ -23,13 +24,14 @@ type NotificationProducer interface {
 type producer struct {
 	asyncProducer sarama.AsyncProducer
 	topic         string
+	routes        []config.NotificationRoute
 	log           *slog.Logger
 	wg            *sync.WaitGroup
 	closeOnce     sync.Once
 }
 
-// NewProducer uses DI to inject AsyncProducer, logger, topic, and WaitGroup.
-func NewProducer(asyncProducer sarama.AsyncProducer, topic string, log *slog.Logger, wg *sync.WaitGroup) NotificationProducer {
+// NewProducer uses DI to inject AsyncProducer, logger, topic, label routes, and WaitGroup.
+func NewProducer(asyncProducer sarama.AsyncProducer, topic string, routes []config.NotificationRoute, log *slog.Logger, wg *sync.WaitGroup) NotificationProducer {
 	if asyncProducer == nil || log == nil || wg == nil {
 		panic("NewProducer: nil dependencies provided")
 	}
@@ -39,6 +41,7 @@ func NewProducer(asyncProducer sarama.AsyncProducer, topic string, log *slog.Log
 	return &producer{
 		asyncProducer: asyncProducer,
 		topic:         topic,
+		routes:        routes,
 		log:           log,
 		wg:            wg,
 	}
@@ -95,7 +98,18 @@ func (p *producer) handleErrors(ctx context.Context) {
 	}
 }
 
-// Publish sends a notification to the Kafka topic
+// topicFor picks the topic of the first route matching the monitor's labels,
+// or the default topic
+func (p *producer) topicFor(notif model.Notification) string {
+	for _, route := range p.routes {
+		if route.Selector.Matches(notif.Labels) {
+			return route.Topic
+		}
+	}
+	return p.topic
+}
+
+// Publish sends a notification to the Kafka topic its labels route it to
 func (p *producer) Publish(ctx context.Context, notif model.Notification) error {
 	p.log.Info("Kafka publish called ")
 	data, err := json.Marshal(notif)
@@ -106,8 +120,9 @@ func (p *producer) Publish(ctx context.Context, notif model.Notification) error
 		return fmt.Errorf("failed to marshal notification: %w", err)
 	}
 
+	topic := p.topicFor(notif)
 	msg := &sarama.ProducerMessage{
-		Topic:     p.topic,
+		Topic:     topic,
 		Key:       sarama.StringEncoder(notif.UrlID),
 		Value:     sarama.ByteEncoder(data),
 		Timestamp: time.Now(),
@@ -116,7 +131,7 @@ func (p *producer) Publish(ctx context.Context, notif model.Notification) error
 	select {
 	case p.asyncProducer.Input() <- msg:
 		p.log.Info("Message queued to Kafka",
-			slog.String("topic", p.topic),
+			slog.String("topic", topic),
 			slog.String("key", notif.UrlID),
 			slog.Any("notification", notif))
 		return nil
@@ -0,0 +1,23 @@
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/IBM/sarama"
+)
+
+// NewAsyncProducer connects a sarama async producer with the settings shared by
+// the API and checker binaries.
+func NewAsyncProducer(brokers []string, clientID string) (sarama.AsyncProducer, error) {
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Producer.RequiredAcks = sarama.WaitForAll // Acks from all replicas
+	saramaConfig.Producer.Retry.Max = 5
+	saramaConfig.Producer.Return.Successes = true
+	saramaConfig.ClientID = clientID
+
+	p, err := sarama.NewAsyncProducer(brokers, saramaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sarama producer: %w", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,133 @@
+// Package labels validates monitor labels and parses the selectors that
+// match them, in the style of Kubernetes label selectors.
+package labels
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Requirement operators
+const (
+	OpEquals    = "="
+	OpNotEquals = "!="
+	OpExists    = "exists"
+	OpNotExists = "!exists"
+)
+
+// Limits on a monitor's labels
+const (
+	MaxLabels      = 32
+	MaxKeyLength   = 63
+	MaxValueLength = 63
+)
+
+var (
+	keyPattern   = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]*[A-Za-z0-9])?$`)
+	valuePattern = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?)?$`)
+)
+
+// Requirement is one condition of a selector. Value is unused by the
+// exists operators.
+type Requirement struct {
+	Key   string `json:"key"`
+	Op    string `json:"op"`
+	Value string `json:"value,omitempty"`
+}
+
+// Selector matches labels meeting all of its requirements. The empty selector
+// matches everything.
+type Selector []Requirement
+
+// Parse reads a comma-separated selector such as "env=prod,team!=payments,tier,!canary":
+// key=value, key!=value, key (the label is set) and !key (it isn't).
+func Parse(s string) (Selector, error) {
+	var sel Selector
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var r Requirement
+		switch {
+		case strings.Contains(part, "!="):
+			key, value, _ := strings.Cut(part, "!=")
+			r = Requirement{Key: strings.TrimSpace(key), Op: OpNotEquals, Value: strings.TrimSpace(value)}
+		case strings.Contains(part, "="):
+			key, value, _ := strings.Cut(part, "=")
+			r = Requirement{Key: strings.TrimSpace(key), Op: OpEquals, Value: strings.TrimSpace(strings.TrimPrefix(value, "="))}
+		case strings.HasPrefix(part, "!"):
+			r = Requirement{Key: strings.TrimSpace(part[1:]), Op: OpNotExists}
+		default:
+			r = Requirement{Key: part, Op: OpExists}
+		}
+		if !keyPattern.MatchString(r.Key) || len(r.Key) > MaxKeyLength {
+			return nil, fmt.Errorf("invalid label key %q in selector", r.Key)
+		}
+		if !valuePattern.MatchString(r.Value) || len(r.Value) > MaxValueLength {
+			return nil, fmt.Errorf("invalid label value %q in selector", r.Value)
+		}
+		sel = append(sel, r)
+	}
+	return sel, nil
+}
+
+// Matches reports whether labels meet every requirement. A != requirement
+// also matches labels without the key.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		value, ok := labels[r.Key]
+		switch r.Op {
+		case OpEquals:
+			if !ok || value != r.Value {
+				return false
+			}
+		case OpNotEquals:
+			if ok && value == r.Value {
+				return false
+			}
+		case OpExists:
+			if !ok {
+				return false
+			}
+		case OpNotExists:
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String formats the selector the way Parse reads it
+func (s Selector) String() string {
+	parts := make([]string, len(s))
+	for i, r := range s {
+		switch r.Op {
+		case OpExists:
+			parts[i] = r.Key
+		case OpNotExists:
+			parts[i] = "!" + r.Key
+		default:
+			parts[i] = r.Key + r.Op + r.Value
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Validate checks a monitor's labels against the key and value syntax and limits
+func Validate(labels map[string]string) error {
+	if len(labels) > MaxLabels {
+		return fmt.Errorf("a monitor can have at most %d labels", MaxLabels)
+	}
+	for key, value := range labels {
+		if !keyPattern.MatchString(key) || len(key) > MaxKeyLength {
+			return fmt.Errorf("invalid label key %q: use up to %d letters, digits, '.', '_', '-' or '/'", key, MaxKeyLength)
+		}
+		if !valuePattern.MatchString(value) || len(value) > MaxValueLength {
+			return fmt.Errorf("invalid value %q for label %q: use up to %d letters, digits, '.', '_' or '-'", value, key, MaxValueLength)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package labels
+
+import "testing"
+
+// Test_Selector parses selectors and matches them against a label set.
+// Table Driven Test Pattern used
+func Test_Selector(t *testing.T) {
+	labels := map[string]string{"env": "prod", "team": "payments", "tier": "1"}
+
+	tests := []struct {
+		selector string
+		want     bool
+		wantErr  bool
+	}{
+		{selector: "", want: true},
+		{selector: "env=prod", want: true},
+		{selector: "env==prod", want: true},
+		{selector: "env=staging", want: false},
+		{selector: "env=prod,team!=payments", want: false},
+		{selector: "team!=search", want: true},
+		{selector: "region!=eu", want: true},
+		{selector: "tier", want: true},
+		{selector: "region", want: false},
+		{selector: "!canary, env = prod", want: true},
+		{selector: "!tier", want: false},
+		{selector: "bad key=x", wantErr: true},
+		{selector: "env=a b", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			sel, err := Parse(tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.selector, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := sel.Matches(labels); got != tt.want {
+				t.Errorf("Parse(%q).Matches() = %v, want %v", tt.selector, got, tt.want)
+			}
+			if again, err := Parse(sel.String()); err != nil || again.String() != sel.String() {
+				t.Errorf("String() = %q does not round-trip", sel.String())
+			}
+		})
+	}
+}
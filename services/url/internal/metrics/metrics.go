@@ -38,8 +38,65 @@ var (
 		},
 		[]string{"status"},
 	)
+
+	// URLCheckPhaseDuration breaks HTTP check durations down by phase:
+	// dns_lookup, tcp_connect, tls_handshake, ttfb and transfer
+	URLCheckPhaseDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "hcaas_url_check_phase_duration_seconds",
+			Help:    "Duration of each phase of HTTP health checks",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"phase"},
+	)
+
+	// URLCheckOutcomes counts checks that passed on the first try, passed
+	// after retries, or failed once retries were used up
+	URLCheckOutcomes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hcaas_url_check_outcomes_total",
+			Help: "Number of URL checks by outcome: first_try, after_retry or failed",
+		},
+		[]string{"outcome"},
+	)
+
+	// CheckerScheduleLag measures how late checks start compared to when they were due
+	CheckerScheduleLag = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "hcaas_checker_schedule_lag_seconds",
+			Help:    "Delay between a check becoming due and a worker picking it up",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30, 60},
+		},
+	)
+
+	// CheckerScheduledMonitors is the number of monitors known to the scheduler
+	CheckerScheduledMonitors = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "hcaas_checker_scheduled_monitors",
+			Help: "Number of monitors in the checker schedule",
+		},
+	)
+
+	// CheckerBusyWorkers is the number of workers currently running a check
+	CheckerBusyWorkers = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "hcaas_checker_busy_workers",
+			Help: "Number of checker workers currently running a check",
+		},
+	)
+
+	// CheckerOwnedShards is the number of shard leases held by this replica
+	CheckerOwnedShards = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "hcaas_checker_owned_shards",
+			Help: "Number of shard leases held by this checker replica",
+		},
+	)
 )
 
 func Init() {
-	prometheus.MustRegister(RequestCount, RequestDuration, URLCheckStatus, URLCheckDuration)
+	prometheus.MustRegister(
+		RequestCount, RequestDuration, URLCheckStatus, URLCheckDuration, URLCheckPhaseDuration, URLCheckOutcomes,
+		CheckerScheduleLag, CheckerScheduledMonitors, CheckerBusyWorkers, CheckerOwnedShards,
+	)
 }
@@ -0,0 +1,23 @@
+package model
+
+// Assertion types understood by the checker
+const (
+	AssertStatusCode   = "status_code"   // Values: "200", "2xx", "200-299"
+	AssertBodyContains = "body_contains" // Value: substring
+	AssertBodyRegex    = "body_regex"    // Value: regular expression
+	AssertJSONPath     = "json_path"     // Target: path such as "$.status", Value: expected value
+	AssertHeader       = "header"        // Target: header name, Value: optional expected value
+	AssertNoHeader     = "no_header"     // Target: header name that must not be present
+	AssertResponseTime = "response_time" // Max: upper bound for the request duration
+)
+
+// Assertion is one condition a check response must satisfy to be considered healthy.
+// Which fields are used depends on Type.
+type Assertion struct {
+	Type   string   `json:"type"`
+	Target string   `json:"target,omitempty"`
+	Value  string   `json:"value,omitempty"`
+	Values []string `json:"values,omitempty"`
+	Negate bool     `json:"negate,omitempty"`
+	Max    Duration `json:"max,omitempty"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// CertInfo describes the certificate a TLS endpoint presented
+type CertInfo struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	SANs      []string  `json:"sans,omitempty"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	Valid     bool      `json:"valid"`           // chain is trusted, unexpired and matches the hostname
+	Error     string    `json:"error,omitempty"` // why the chain failed verification
+}
+
+// TLSCheck configures a tls monitor
+type TLSCheck struct {
+	ServerName string `json:"server_name,omitempty"` // SNI and hostname to verify; the address host when empty
+}
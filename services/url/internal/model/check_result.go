@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// Error classes attached to failed checks
+const (
+	ErrorClassTimeout    = "timeout"
+	ErrorClassDNS        = "dns"
+	ErrorClassRefused    = "connection_refused"
+	ErrorClassConnection = "connection"
+	ErrorClassTLS        = "tls"
+	ErrorClassRequest    = "invalid_request"
+	ErrorClassAssertion  = "assertion"
+	ErrorClassAuth       = "auth" // the monitor's credential couldn't be loaded or exchanged for a token
+
+	ErrorClassMissedHeartbeat = "missed_heartbeat"
+	ErrorClassJobFailed       = "job_failed"
+)
+
+// CheckResult is the outcome of a single check run
+type CheckResult struct {
+	ID               int64     `json:"id"`
+	URLID            string    `json:"url_id"`
+	CheckedAt        time.Time `json:"checked_at"`
+	Status           string    `json:"status"` // "healthy" or "unhealthy"
+	StatusCode       int       `json:"status_code,omitempty"`
+	Latency          Duration  `json:"latency"`
+	Error            string    `json:"error,omitempty"`
+	ErrorClass       string    `json:"error_class,omitempty"`
+	FailedAssertions []string  `json:"failed_assertions,omitempty"`
+	Cert             *CertInfo `json:"cert,omitempty"`     // https and tls monitors
+	Attempts         int       `json:"attempts,omitempty"` // tries this run took, more than 1 when retried
+
+	// Where the time went, for http monitors that got a response
+	Timings *HTTPTimings `json:"timings,omitempty"`
+
+	// Content monitors: hash of the normalized body, and the body itself for
+	// the checker to compare; only the hash is stored
+	ContentHash string `json:"content_hash,omitempty"`
+	Content     string `json:"-"`
+
+	// Transaction monitors: the steps that ran, in order, and the one that failed
+	Steps      []StepResult `json:"steps,omitempty"`
+	FailedStep string       `json:"failed_step,omitempty"`
+}
+
+// HTTPTimings breaks an HTTP check's latency into phases. The phases follow
+// each other, so a phase that didn't happen, like DNS for an IP address or the
+// connection setup on a reused connection, is zero.
+type HTTPTimings struct {
+	DNSLookup    Duration `json:"dns_lookup"`
+	TCPConnect   Duration `json:"tcp_connect"`
+	TLSHandshake Duration `json:"tls_handshake"`
+	FirstByte    Duration `json:"ttfb"`     // request written to first response byte: the server's own time
+	Transfer     Duration `json:"transfer"` // first byte to the end of the body
+}
+
+// CheckQuery filters the check history of one URL. From is inclusive, To exclusive;
+// zero values leave that end open.
+type CheckQuery struct {
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Cursor string
+}
+
+// Page is one page of a cursor-paginated listing
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
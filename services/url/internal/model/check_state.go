@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// CheckState holds the counters behind a URL's confirmed status. It is persisted
+// with the URL so a restart doesn't reset thresholds or flap history.
+type CheckState struct {
+	ConsecutiveFailures  int         `json:"consecutive_failures"`
+	ConsecutiveSuccesses int         `json:"consecutive_successes"`
+	Confirmed            string      `json:"confirmed,omitempty"`      // last confirmed healthy/unhealthy, kept while flapping
+	RecentChanges        []time.Time `json:"recent_changes,omitempty"` // confirmed changes inside the flap window
+
+	CertExpiryNotified int  `json:"cert_expiry_notified,omitempty"` // smallest expiry threshold (days) already announced
+	CertInvalid        bool `json:"cert_invalid,omitempty"`         // cert_invalid was announced and not yet resolved
+
+	RunStartedAt *time.Time `json:"run_started_at,omitempty"` // heartbeat monitors: the job signalled /start
+
+	ContentBaseline string `json:"content_baseline,omitempty"` // hash of the accepted content
+	ContentChanged  string `json:"content_changed,omitempty"`  // hash of the changed content already announced
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// ContentCheck turns on change detection for an http monitor. Before hashing,
+// the body's line endings are unified, matches of Ignore removed and trailing
+// whitespace trimmed from every line.
+type ContentCheck struct {
+	Ignore []string `json:"ignore,omitempty"` // regexes for timestamps, nonces and other expected churn
+}
+
+// ContentSnapshot is a normalized response body and its hash
+type ContentSnapshot struct {
+	Hash   string    `json:"hash"`
+	Body   string    `json:"body"`
+	SeenAt time.Time `json:"seen_at"`
+}
+
+// ContentReview shows a monitor's accepted content next to the change waiting
+// to be accepted, if any
+type ContentReview struct {
+	Baseline *ContentSnapshot `json:"baseline"`
+	Changed  *ContentSnapshot `json:"changed,omitempty"`
+	Diff     string           `json:"diff,omitempty"` // unified diff from Baseline to Changed
+}
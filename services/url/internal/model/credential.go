@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// Credential types a monitor can authenticate with
+const (
+	CredentialBasic  = "basic"   // Username and Secret as HTTP Basic auth
+	CredentialBearer = "bearer"  // Secret as a bearer token
+	CredentialAPIKey = "api_key" // Secret in the Header request header
+	CredentialOAuth2 = "oauth2"  // client-credentials token from TokenURL for ClientID and Secret
+
+	// CredentialClientCert is a TLS client certificate (Certificate) and its
+	// private key (Secret), referenced from a monitor's transport settings
+	CredentialClientCert = "client_cert"
+)
+
+// DefaultAPIKeyHeader carries api_key credentials without a Header
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// Credential is stored authentication an http monitor references by ID.
+// Secret is write-only: it is encrypted at rest and never returned.
+type Credential struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+
+	Username string   `json:"username,omitempty"`  // basic
+	Header   string   `json:"header,omitempty"`    // api_key
+	TokenURL string   `json:"token_url,omitempty"` // oauth2
+	ClientID string   `json:"client_id,omitempty"` // oauth2
+	Scopes   []string `json:"scopes,omitempty"`    // oauth2
+
+	Certificate string `json:"certificate,omitempty"` // client_cert: PEM certificate chain
+
+	// Secret is the password, token, API key or client secret
+	Secret string `json:"secret,omitempty"`
+	// Sealed is Secret as stored: encrypted under its own data key
+	Sealed SealedSecret `json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SealedSecret is an envelope-encrypted secret and its wrapped data key
+type SealedSecret struct {
+	WrappedKey []byte
+	Ciphertext []byte
+}
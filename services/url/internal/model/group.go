@@ -0,0 +1,17 @@
+package model
+
+// StatusPaused is the aggregate status of a group whose monitors are all paused
+const StatusPaused = "paused"
+
+// GroupSummary is the aggregate state of a named group of monitors. The
+// per-status counts cover the monitors being checked, not the paused ones.
+type GroupSummary struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"` // worst status of the monitors being checked
+	Total     int    `json:"total"`
+	Healthy   int    `json:"healthy"`   // healthy or up
+	Unhealthy int    `json:"unhealthy"` // unhealthy or down
+	Flapping  int    `json:"flapping"`
+	Unknown   int    `json:"unknown"` // not checked yet
+	Paused    int    `json:"paused"`
+}
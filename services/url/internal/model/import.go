@@ -0,0 +1,29 @@
+package model
+
+// What an import does with a row
+const (
+	ImportCreate  = "create"
+	ImportUpdate  = "update"
+	ImportSkip    = "skip" // matches the monitor as it is
+	ImportInvalid = "invalid"
+)
+
+// ImportReport is what an import did, or would do on a dry run. Nothing is
+// written when any row is invalid.
+type ImportReport struct {
+	DryRun  bool        `json:"dry_run"`
+	Created int         `json:"created"`
+	Updated int         `json:"updated"`
+	Skipped int         `json:"skipped"`
+	Invalid int         `json:"invalid"`
+	Rows    []ImportRow `json:"rows"`
+}
+
+// ImportRow is the outcome of one row, numbered from 1
+type ImportRow struct {
+	Row     int    `json:"row"`
+	Address string `json:"address,omitempty"`
+	Action  string `json:"action"`
+	ID      string `json:"id,omitempty"` // of the monitor created or updated
+	Error   string `json:"error,omitempty"`
+}
@@ -0,0 +1,75 @@
+package model
+
+import "time"
+
+// Monitor types: how the checker probes an Address
+const (
+	MonitorHTTP = "http" // Address is an http(s) URL
+	MonitorTCP  = "tcp"  // Address is host:port
+	MonitorDNS  = "dns"  // Address is the name to resolve
+	MonitorTLS  = "tls"  // Address is host:port
+	MonitorGRPC = "grpc" // Address is host:port of a grpc.health.v1.Health server
+
+	// MonitorHeartbeat is push-based: the job pings Address (/heartbeat/{token})
+	// every Interval and the monitor goes down when a ping is overdue.
+	MonitorHeartbeat = "heartbeat"
+)
+
+// TCPCheck configures a tcp monitor. Without Send or Expect the check only
+// verifies the port accepts connections.
+type TCPCheck struct {
+	Send   string `json:"send,omitempty"`   // written right after connecting
+	Expect string `json:"expect,omitempty"` // must appear in what the server sends back
+}
+
+// DNS record types a dns monitor can resolve
+const (
+	DNSRecordA     = "A"
+	DNSRecordAAAA  = "AAAA"
+	DNSRecordCNAME = "CNAME"
+	DNSRecordMX    = "MX"
+	DNSRecordTXT   = "TXT"
+)
+
+// DNSCheck configures a dns monitor. Without Expected the check passes as long
+// as the name resolves to at least one record.
+type DNSCheck struct {
+	RecordType string   `json:"record_type"`        // one of the DNSRecord* types, default A
+	Resolver   string   `json:"resolver,omitempty"` // host:port of the DNS server; the system resolver when empty
+	Expected   []string `json:"expected,omitempty"` // exact answer set, order-insensitive; MX answers are "pref host"
+}
+
+// GRPCCheck configures a grpc monitor
+type GRPCCheck struct {
+	Service    string `json:"service,omitempty"`     // service name passed to Health/Check; empty asks about the server as a whole
+	TLS        bool   `json:"tls,omitempty"`         // connect with TLS instead of plaintext
+	ServerName string `json:"server_name,omitempty"` // TLS host name to verify; the address host when empty
+}
+
+// HeartbeatCheck configures a heartbeat monitor. The expected period is the
+// URL's Interval.
+type HeartbeatCheck struct {
+	Token string   `json:"token"` // secret part of the ping URL, generated on creation
+	Grace Duration `json:"grace"` // how late a ping may be before the monitor fails
+}
+
+// Heartbeat ping kinds
+const (
+	HeartbeatSuccess  = "success"   // POST /heartbeat/{token}
+	HeartbeatStart    = "start"     // POST /heartbeat/{token}/start
+	HeartbeatFail     = "fail"      // POST /heartbeat/{token}/fail
+	HeartbeatExitCode = "exit_code" // POST /heartbeat/{token}/{code}
+)
+
+// HeartbeatPing is one check-in from a job
+type HeartbeatPing struct {
+	Kind     string
+	ExitCode int    // HeartbeatExitCode only; 0 is success
+	Body     string // optional output the job sent along, kept with failures
+}
+
+// Heartbeat defaults and limits
+const (
+	DefaultHeartbeatGrace = Duration(5 * time.Minute)
+	MaxHeartbeatPeriod    = Duration(31 * 24 * time.Hour)
+)
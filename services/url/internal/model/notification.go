@@ -4,12 +4,29 @@ import (
 	"time"
 )
 
+// Notification types published by the checker
+const (
+	NotificationURLDown      = "url_down"
+	NotificationURLRecovered = "url_recovered"
+	NotificationURLFlapping  = "url_flapping"
+	NotificationCertExpiring = "cert_expiring"
+	NotificationCertInvalid  = "cert_invalid"
+
+	NotificationContentChanged = "content_changed"
+)
+
 // Notification struct represents a notification
 // This shall match the message model consumed by notification service
 type Notification struct {
-	UrlID     string    `json:"url_id"`
-	Type      string    `json:"type"`
-	Message   string    `json:"message"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	UrlID           string            `json:"url_id"`
+	Name            string            `json:"name,omitempty"`   // of the monitor
+	Group           string            `json:"group,omitempty"`  // of the monitor
+	Labels          map[string]string `json:"labels,omitempty"` // of the monitor; notifications are routed by them
+	Type            string            `json:"type"`
+	Message         string            `json:"message"`
+	Status          string            `json:"status"`
+	PreviousStatus  string            `json:"previous_status,omitempty"`
+	DowntimeSeconds int64             `json:"downtime_seconds,omitempty"` // set on url_recovered
+	Diff            string            `json:"diff,omitempty"`             // set on content_changed: unified diff from the baseline
+	CreatedAt       time.Time         `json:"created_at"`
 }
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// RetryPolicy re-runs a failing check within the same run, so a transient
+// error only counts as a failure once the retries are used up
+type RetryPolicy struct {
+	Count   int      `json:"count"`   // retries after the first attempt
+	Backoff Duration `json:"backoff"` // wait before the first retry, doubled for each one after it
+
+	// What is retried: failures of these error classes, and responses with
+	// these status codes. DefaultRetryErrorClasses when both are empty.
+	ErrorClasses []string `json:"error_classes,omitempty"`
+	StatusCodes  []int    `json:"status_codes,omitempty"`
+}
+
+// Retry defaults and limits
+const (
+	MaxRetries          = 5
+	DefaultRetryBackoff = Duration(1 * time.Second)
+	MaxRetryBackoff     = Duration(30 * time.Second) // also caps the doubled waits
+)
+
+// DefaultRetryErrorClasses are the failures that are usually transient
+var DefaultRetryErrorClasses = []string{ErrorClassTimeout, ErrorClassConnection, ErrorClassRefused, ErrorClassDNS}
+
+// RetryableErrorClasses are the error classes a policy may list
+var RetryableErrorClasses = map[string]bool{
+	ErrorClassTimeout:    true,
+	ErrorClassDNS:        true,
+	ErrorClassRefused:    true,
+	ErrorClassConnection: true,
+	ErrorClassTLS:        true,
+	ErrorClassAssertion:  true,
+	ErrorClassAuth:       true,
+}
+
+// RetryDelay is the wait before retry n (0-based): Backoff doubled n times,
+// capped at MaxRetryBackoff
+func RetryDelay(p *RetryPolicy, n int) Duration {
+	delay := p.Backoff
+	for i := 0; i < n && delay < MaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, MaxRetryBackoff)
+}
@@ -0,0 +1,45 @@
+package model
+
+import "encoding/json"
+
+// What a sync does with a monitor
+const (
+	SyncCreate    = "create"
+	SyncUpdate    = "update"
+	SyncAdopt     = "adopt" // an unmanaged monitor becomes managed
+	SyncDelete    = "delete"
+	SyncUnchanged = "unchanged"
+	SyncInvalid   = "invalid"
+)
+
+// SyncDocument is the full desired set of a user's managed monitors, or of
+// those in Group when it is set. Each monitor is a JSON object as POST /urls
+// takes it, plus the key it is matched by.
+type SyncDocument struct {
+	Group    string            `json:"group,omitempty"`
+	Monitors []json.RawMessage `json:"monitors"`
+}
+
+// SyncPlan is what a sync did, or would do on a dry run. Nothing is written
+// when any monitor is invalid.
+type SyncPlan struct {
+	DryRun    bool         `json:"dry_run"`
+	Group     string       `json:"group,omitempty"`
+	Created   int          `json:"created"`
+	Updated   int          `json:"updated"` // adopted monitors included
+	Deleted   int          `json:"deleted"`
+	Unchanged int          `json:"unchanged"`
+	Invalid   int          `json:"invalid"`
+	Changes   []SyncChange `json:"changes"`
+}
+
+// SyncChange is the plan for one monitor
+type SyncChange struct {
+	Key     string   `json:"key"`
+	Action  string   `json:"action"`
+	ID      string   `json:"id,omitempty"`
+	Address string   `json:"address,omitempty"`
+	Fields  []string `json:"fields,omitempty"`  // changed by an update
+	Drifted bool     `json:"drifted,omitempty"` // edited by hand since the last sync; the edits are overwritten
+	Error   string   `json:"error,omitempty"`
+}
@@ -0,0 +1,52 @@
+package model
+
+// MonitorTransaction runs an ordered list of HTTP steps; the monitor passes
+// only when every step does. Address is informational, the first step's URL
+// by default.
+const MonitorTransaction = "transaction"
+
+// Where an extraction reads its value from
+const (
+	ExtractJSONPath = "json_path" // Target is a JSON path into the body
+	ExtractHeader   = "header"    // Target is a response header name
+	ExtractRegex    = "regex"     // Target is a regex over the body; the first capture group, or the whole match
+)
+
+// Transaction limits
+const MaxTransactionSteps = 10
+
+// TransactionCheck configures a transaction monitor
+type TransactionCheck struct {
+	Steps []TransactionStep `json:"steps"`
+}
+
+// TransactionStep is one request in a transaction. URL, header values and
+// Body may reference values extracted by earlier steps as {{name}}.
+type TransactionStep struct {
+	Name       string            `json:"name"` // "step N" when empty
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	Assertions []Assertion       `json:"assertions,omitempty"`
+	Extract    []Extraction      `json:"extract,omitempty"`
+}
+
+// Extraction captures a value from a step's response for later steps
+type Extraction struct {
+	Name   string `json:"name"` // referenced as {{name}}
+	From   string `json:"from"` // one of the Extract* sources
+	Target string `json:"target"`
+}
+
+// StepResult is the outcome of one transaction step
+type StepResult struct {
+	Name             string   `json:"name"`
+	Status           string   `json:"status"` // "healthy" or "unhealthy"
+	StatusCode       int      `json:"status_code,omitempty"`
+	Latency          Duration `json:"latency"`
+	Error            string   `json:"error,omitempty"`
+	FailedAssertions []string `json:"failed_assertions,omitempty"`
+
+	Timings *HTTPTimings `json:"timings,omitempty"`
+}
@@ -0,0 +1,24 @@
+package model
+
+// TransportConfig customises how the checker connects for an http or
+// transaction monitor. Monitors with identical settings share a client.
+type TransportConfig struct {
+	ClientCertID       string `json:"client_cert_id,omitempty"` // client_cert credential presented for mTLS
+	CABundle           string `json:"ca_bundle,omitempty"`      // PEM roots trusted instead of the system pool
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+
+	Proxy string `json:"proxy,omitempty"` // http://, https:// or socks5:// proxy URL
+
+	FollowRedirects *bool `json:"follow_redirects,omitempty"` // true when unset
+	MaxRedirects    int   `json:"max_redirects,omitempty"`    // hops to follow, DefaultMaxRedirects when unset
+
+	IPVersion     int               `json:"ip_version,omitempty"`     // 4 or 6 to force one; either when unset
+	Resolver      string            `json:"resolver,omitempty"`       // host:port of the DNS server; the system resolver when empty
+	HostOverrides map[string]string `json:"host_overrides,omitempty"` // host name to the IP to connect to instead, like curl --resolve
+}
+
+// Redirect limits
+const (
+	DefaultMaxRedirects = 10
+	MaxRedirectHops     = 20
+)
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// StatusChange marks the point a URL's check outcome switched to Status
+type StatusChange struct {
+	At     time.Time `json:"at"`
+	Status string    `json:"status"`
+}
+
+// UptimeStats summarises availability over a window. Only time covered by
+// healthy or unhealthy results counts as monitored.
+type UptimeStats struct {
+	UptimePercent *float64 `json:"uptime_percent"` // nil when nothing was monitored
+	Monitored     Duration `json:"monitored"`
+	Downtime      Duration `json:"downtime"`
+	Incidents     int      `json:"incidents"`
+	MTTR          Duration `json:"mttr"` // mean time to recovery
+	MTBF          Duration `json:"mtbf"` // mean time between failures
+}
+
+// UptimeReport is the availability of a single URL over [From, To)
+type UptimeReport struct {
+	URLID   string    `json:"url_id"`
+	Address string    `json:"address"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	UptimeStats
+}
+
+// UserReport aggregates the availability of every URL a user owns
+type UserReport struct {
+	From    time.Time      `json:"from"`
+	To      time.Time      `json:"to"`
+	Overall UptimeStats    `json:"overall"`
+	URLs    []UptimeReport `json:"urls"`
+}
@@ -8,11 +8,75 @@ const (
 )
 
 type URL struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	Address   string    `json:"address"`
-	Status    string    `json:"status"`     // "up" or "down"
-	CheckedAt time.Time `json:"checked_at"` // last checked time
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Name      string            `json:"name,omitempty"` // human-readable; listings search it with the address
+	Tags      []string          `json:"tags,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"` // key/value pairs matched by label selectors
+	Group     string            `json:"group,omitempty"`  // named group, paused and reported on as one
+	Address   string            `json:"address"`
+	Status    string            `json:"status"`     // "up" or "down"
+	CheckedAt time.Time         `json:"checked_at"` // last checked time
+	Latency   Duration          `json:"latency"`    // of the last check
+
+	StatusChangedAt time.Time `json:"status_changed_at"` // when Status last changed value
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"` // last config change, not bumped by checks
+
+	// Declarative sync matches monitors by Key. Managed ones were created by
+	// sync; Drifted is set when one is edited by hand and cleared by the next sync.
+	Key     string `json:"key,omitempty"`
+	Managed bool   `json:"managed"`
+	Drifted bool   `json:"drifted"`
+
+	Paused    bool       `json:"paused"`               // not checked until resumed
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // soft-deleted; cleared on restore
+
+	// Check spec: how the checker probes this URL
+	Type     string            `json:"type"` // one of the Monitor* types, MonitorHTTP by default
+	Method   string            `json:"method"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     string            `json:"body,omitempty"`
+	Timeout  Duration          `json:"timeout"`
+	Interval Duration          `json:"interval"`
+
+	TCP  *TCPCheck  `json:"tcp,omitempty"`  // tcp monitors only
+	DNS  *DNSCheck  `json:"dns,omitempty"`  // dns monitors only
+	TLS  *TLSCheck  `json:"tls,omitempty"`  // tls monitors only
+	GRPC *GRPCCheck `json:"grpc,omitempty"` // grpc monitors only
+
+	Heartbeat  *HeartbeatCheck `json:"heartbeat,omitempty"`    // heartbeat monitors only
+	LastPingAt *time.Time      `json:"last_ping_at,omitempty"` // heartbeat monitors: last ping, or resume; missed checks don't move it
+
+	Transaction *TransactionCheck `json:"transaction,omitempty"` // transaction monitors only
+
+	// Stored credential the checker authenticates with, http monitors only
+	CredentialID string `json:"credential_id,omitempty"`
+
+	Transport *TransportConfig `json:"transport,omitempty"` // http and transaction monitors only
+
+	Retry *RetryPolicy `json:"retry,omitempty"` // retries within a check run; none when unset
+
+	// Content change detection, http monitors only. The snapshots are served by
+	// GET /urls/{id}/content rather than inline, as bodies can be large.
+	Content         *ContentCheck    `json:"content,omitempty"`
+	ContentBaseline *ContentSnapshot `json:"-"` // last accepted content
+	ContentChanged  *ContentSnapshot `json:"-"` // latest content that differs from the baseline
+
+	// Certificate seen by the latest check of an https or tls monitor
+	Cert *CertInfo `json:"cert,omitempty"`
+
+	// Assertions the response must satisfy; without a status_code assertion any status below 400 passes
+	Assertions []Assertion `json:"assertions,omitempty"`
+
+	// Confirmation thresholds and flap detection
+	FailureThreshold  int      `json:"failure_threshold"`  // consecutive failures before going down
+	RecoveryThreshold int      `json:"recovery_threshold"` // consecutive successes before recovering
+	FlapThreshold     int      `json:"flap_threshold"`     // status changes within FlapWindow that mark it flapping; 0 disables
+	FlapWindow        Duration `json:"flap_window"`
+
+	State CheckState `json:"state"`
 }
 
 const (
@@ -20,3 +84,26 @@ const (
 	StatusUP      = "up"
 	StatusDown    = "down"
 )
+
+// Check outcomes written by the checker
+const (
+	StatusHealthy   = "healthy"
+	StatusUnhealthy = "unhealthy"
+	StatusFlapping  = "flapping"
+)
+
+// Check spec defaults and limits
+const (
+	DefaultCheckMethod   = "GET"
+	DefaultCheckTimeout  = Duration(10 * time.Second)
+	DefaultCheckInterval = Duration(1 * time.Minute)
+
+	DefaultThreshold  = 1
+	MaxThreshold      = 100
+	DefaultFlapWindow = Duration(30 * time.Minute)
+	MaxFlapWindow     = Duration(24 * time.Hour)
+
+	MaxCheckTimeout  = Duration(1 * time.Minute)
+	MinCheckInterval = Duration(10 * time.Second)
+	MaxCheckInterval = Duration(24 * time.Hour)
+)
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/labels"
+)
+
+// Sort orders for monitor listings
+const (
+	URLSortCreated    = "created" // the default
+	URLSortName       = "name"
+	URLSortStatus     = "status"
+	URLSortLatency    = "latency"     // of the last check
+	URLSortLastChange = "last_change" // when the status last changed
+)
+
+// URLQuery filters, sorts and pages a user's monitors. Zero values don't filter;
+// CheckedFrom is inclusive and CheckedTo exclusive.
+type URLQuery struct {
+	Status      []string // any of
+	Type        []string // any of
+	Tags        []string // all of
+	Group       string
+	Selector    labels.Selector
+	Paused      *bool
+	CheckedFrom time.Time
+	CheckedTo   time.Time
+	Search      string // case-insensitive substring of the address or name
+
+	Sort   string // one of the URLSort* orders
+	Desc   bool
+	Limit  int
+	Cursor string
+}
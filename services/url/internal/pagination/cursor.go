@@ -0,0 +1,53 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Limits shared by every paginated listing
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+// Cursor marks the last row of a page: the value of the sort column and the row id
+// used as a tie-breaker. It is handed to clients as an opaque string.
+type Cursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// Encode returns the opaque form of the cursor
+func Encode(c Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode parses a cursor produced by Encode; an empty string yields nil
+func Decode(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	return &c, nil
+}
+
+// ClampLimit applies the default and maximum page sizes
+func ClampLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	if limit > MaxLimit {
+		return MaxLimit
+	}
+	return limit
+}
@@ -15,7 +15,13 @@ import (
 	customMiddleware "github.com/samims/hcaas/services/url/internal/middleware"
 )
 
-func NewRouter(h *handler.URLHandler, healthHandler *handler.HealthHandler, logger *slog.Logger) http.Handler {
+func NewRouter(
+	h *handler.URLHandler,
+	healthHandler *handler.HealthHandler,
+	heartbeatHandler *handler.HeartbeatHandler,
+	credentialHandler *handler.CredentialHandler,
+	logger *slog.Logger,
+) http.Handler {
 	r := chi.NewRouter()
 	authSvcURL := os.Getenv("AUTH_SVC_URL")
 	authMiddleware := customMiddleware.AuthMiddleware(authSvcURL, logger)
@@ -31,8 +37,46 @@ func NewRouter(h *handler.URLHandler, healthHandler *handler.HealthHandler, logg
 		r.Use(authMiddleware)
 		r.Get("/", h.GetAll)
 		r.Get("/{id}", h.GetByID)
-		r.Get("/me", h.GetAllByUserID)
+		r.Get("/{id}/checks", h.GetChecks)
+		r.Get("/{id}/uptime", h.GetUptime)
+		r.Get("/{id}/content", h.GetContent)
+		r.Post("/{id}/content/accept", h.AcceptContent)
+		r.Get("/me", h.List)
+		r.Get("/me/report", h.GetUserReport)
+		r.Get("/export", h.Export)
 		r.Post("/", h.Add)
+		r.Post("/import", h.Import)
+		r.Post("/sync", h.Sync)
+		r.Put("/{id}", h.Update)
+		r.Patch("/{id}", h.Patch)
+		r.Delete("/{id}", h.Delete)
+		r.Post("/{id}/restore", h.Restore)
+		r.Post("/{id}/pause", h.Pause)
+		r.Post("/{id}/resume", h.Resume)
+		r.Patch("/{id}/status", h.UpdateStatus)
+	})
+
+	r.Route("/groups", func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Get("/", h.ListGroups)
+		r.Get("/{name}", h.GetGroup)
+		r.Post("/{name}/pause", h.PauseGroup)
+		r.Post("/{name}/resume", h.ResumeGroup)
+	})
+
+	r.Route("/credentials", func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Get("/", credentialHandler.List)
+		r.Post("/", credentialHandler.Create)
+		r.Delete("/{id}", credentialHandler.Delete)
+	})
+
+	// Heartbeat pings are unauthenticated; the token in the path identifies the monitor
+	r.Route("/heartbeat/{token}", func(r chi.Router) {
+		r.Post("/", heartbeatHandler.Ping)
+		r.Post("/start", heartbeatHandler.Start)
+		r.Post("/fail", heartbeatHandler.Fail)
+		r.Post("/{code:[0-9]+}", heartbeatHandler.ExitCode)
 	})
 
 	// Health & Readiness Routes
@@ -42,3 +86,15 @@ func NewRouter(h *handler.URLHandler, healthHandler *handler.HealthHandler, logg
 
 	return r
 }
+
+// NewCheckerRouter serves the standalone checker's health and metrics endpoints
+func NewCheckerRouter(healthHandler *handler.HealthHandler) http.Handler {
+	r := chi.NewRouter()
+	r.Use(middleware.Recoverer)
+
+	r.Get("/healthz", healthHandler.Liveness)
+	r.Get("/readyz", healthHandler.Readiness)
+	r.Handle("/metrics", promhttp.Handler())
+
+	return r
+}
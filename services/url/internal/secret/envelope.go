@@ -0,0 +1,113 @@
+// Package secret encrypts values at rest with envelope encryption: every value
+// gets its own random data key, and only that data key is encrypted with the
+// master key from config.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// ErrNoKey is returned when no master key is configured
+var ErrNoKey = errors.New("secret: no master key configured")
+
+// Sealed is an encrypted value with its wrapped data key. Both are a GCM nonce
+// followed by the ciphertext.
+type Sealed struct {
+	WrappedKey []byte
+	Ciphertext []byte
+}
+
+// Envelope seals and opens values under one master key. The zero value, from
+// NewEnvelope(nil), fails every operation with ErrNoKey.
+type Envelope struct {
+	master cipher.AEAD
+}
+
+// NewEnvelope returns an Envelope for a 32-byte AES-256 master key. A nil key
+// yields an Envelope that refuses to work, for deployments without credentials.
+func NewEnvelope(masterKey []byte) (*Envelope, error) {
+	if masterKey == nil {
+		return &Envelope{}, nil
+	}
+	if len(masterKey) != 32 {
+		return nil, fmt.Errorf("secret: master key must be 32 bytes, got %d", len(masterKey))
+	}
+	aead, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{master: aead}, nil
+}
+
+// Seal encrypts plaintext under a fresh data key
+func (e *Envelope) Seal(plaintext []byte) (Sealed, error) {
+	if e.master == nil {
+		return Sealed{}, ErrNoKey
+	}
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return Sealed{}, err
+	}
+	data, err := newGCM(dataKey)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	ciphertext, err := seal(data, plaintext)
+	if err != nil {
+		return Sealed{}, err
+	}
+	wrapped, err := seal(e.master, dataKey)
+	if err != nil {
+		return Sealed{}, err
+	}
+	return Sealed{WrappedKey: wrapped, Ciphertext: ciphertext}, nil
+}
+
+// Open decrypts a value sealed under the same master key
+func (e *Envelope) Open(s Sealed) ([]byte, error) {
+	if e.master == nil {
+		return nil, ErrNoKey
+	}
+	dataKey, err := open(e.master, s.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("secret: unwrap data key: %w", err)
+	}
+	data, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(data, s.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("secret: decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
@@ -0,0 +1,43 @@
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func Test_Envelope(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	env, err := NewEnvelope(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := env.Seal([]byte("hunter2"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if bytes.Contains(sealed.Ciphertext, []byte("hunter2")) {
+		t.Error("Seal() ciphertext contains the plaintext")
+	}
+	got, err := env.Open(sealed)
+	if err != nil || string(got) != "hunter2" {
+		t.Errorf("Open() = %q, %v; want hunter2", got, err)
+	}
+
+	other, _ := NewEnvelope(bytes.Repeat([]byte{8}, 32))
+	if _, err := other.Open(sealed); err == nil {
+		t.Error("Open() with another master key succeeded, want error")
+	}
+
+	tampered := Sealed{WrappedKey: sealed.WrappedKey, Ciphertext: append([]byte{}, sealed.Ciphertext...)}
+	tampered.Ciphertext[len(tampered.Ciphertext)-1] ^= 1
+	if _, err := env.Open(tampered); err == nil {
+		t.Error("Open() of tampered ciphertext succeeded, want error")
+	}
+
+	none, _ := NewEnvelope(nil)
+	if _, err := none.Seal([]byte("x")); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Seal() without a key error = %v, want ErrNoKey", err)
+	}
+}
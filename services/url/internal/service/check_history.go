@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// RecordCheck stores a check run along with the URL's confirmed status and check state.
+// It is not user-scoped; the background checker calls it.
+func (s *urlService) RecordCheck(ctx context.Context, result *model.CheckResult, status string, state model.CheckState) error {
+	if err := s.store.RecordCheck(ctx, result, status, state); err != nil {
+		if appErr.IsNotFound(err) {
+			s.logger.Warn("URL vanished before check was recorded", slog.String("id", result.URLID))
+			return appErr.NewNotFound("URL with ID %s not found", result.URLID)
+		}
+		s.logger.Error("failed to record check",
+			slog.String("id", result.URLID),
+			slog.String("error", err.Error()))
+		return appErr.NewInternal("failed to record check: %v", err)
+	}
+	return nil
+}
+
+// GetChecks returns the check history of a URL owned by the user in ctx
+func (s *urlService) GetChecks(ctx context.Context, id string, q model.CheckQuery) (model.Page[model.CheckResult], error) {
+	s.logger.Info("GetChecks called", slog.String("id", id))
+
+	if _, err := s.findOwned(ctx, id); err != nil {
+		return model.Page[model.CheckResult]{}, err
+	}
+
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return model.Page[model.CheckResult]{}, appErr.NewInvalid("from must be before to")
+	}
+
+	page, err := s.store.FindCheckResults(ctx, id, q)
+	if err != nil {
+		if appErr.IsInvalid(err) {
+			return page, err
+		}
+		s.logger.Error("failed to fetch check history",
+			slog.String("id", id),
+			slog.String("error", err.Error()))
+		return page, appErr.NewInternal("failed to fetch check history: %v", err)
+	}
+
+	s.logger.Info("GetChecks succeeded", slog.String("id", id), slog.Int("count", len(page.Items)))
+	return page, nil
+}
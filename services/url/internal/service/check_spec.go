@@ -0,0 +1,301 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/samims/hcaas/services/url/internal/assertion"
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+var allowedCheckMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// canonicalAddress is the address as a monitor of the type stores it, so rows
+// can be matched with stored monitors before they are validated: dns names are
+// lowercased without the root dot, other addresses only trimmed
+func canonicalAddress(monitorType, address string) string {
+	address = strings.TrimSpace(address)
+	if strings.EqualFold(strings.TrimSpace(monitorType), model.MonitorDNS) {
+		address = strings.TrimSuffix(strings.ToLower(address), ".")
+	}
+	return address
+}
+
+// normalizeCheckSpec fills in defaults for the check spec and rejects values
+// the checker can't honour.
+func normalizeCheckSpec(u *model.URL) error {
+	u.Type = strings.ToLower(strings.TrimSpace(u.Type))
+	if u.Type == "" {
+		u.Type = model.MonitorHTTP
+	}
+
+	var err error
+	switch u.Type {
+	case model.MonitorHTTP:
+		err = normalizeHTTPSpec(u)
+	case model.MonitorTCP:
+		err = normalizeTCPSpec(u)
+	case model.MonitorDNS:
+		err = normalizeDNSSpec(u)
+	case model.MonitorTLS:
+		err = normalizeTLSSpec(u)
+	case model.MonitorGRPC:
+		err = normalizeGRPCSpec(u)
+	case model.MonitorHeartbeat:
+		err = normalizeHeartbeatSpec(u)
+	case model.MonitorTransaction:
+		err = normalizeTransactionSpec(u)
+	default:
+		err = appErr.NewInvalid("unsupported monitor type %q", u.Type)
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkTypeSettings(u); err != nil {
+		return err
+	}
+	if err := normalizeTransport(u.Transport); err != nil {
+		return err
+	}
+
+	if u.Timeout == 0 {
+		u.Timeout = model.DefaultCheckTimeout
+	}
+	if u.Interval == 0 {
+		u.Interval = model.DefaultCheckInterval
+	}
+
+	if u.Timeout < 0 || u.Timeout > model.MaxCheckTimeout {
+		return appErr.NewInvalid("timeout must be between 0 and %s", model.MaxCheckTimeout.Std())
+	}
+	maxInterval := model.MaxCheckInterval
+	if u.Type == model.MonitorHeartbeat {
+		// Jobs may run weekly or monthly
+		maxInterval = model.MaxHeartbeatPeriod
+	}
+	if u.Interval < model.MinCheckInterval || u.Interval > maxInterval {
+		return appErr.NewInvalid("interval must be between %s and %s",
+			model.MinCheckInterval.Std(), maxInterval.Std())
+	}
+	if u.Timeout > u.Interval {
+		return appErr.NewInvalid("timeout %s must not exceed interval %s", u.Timeout.Std(), u.Interval.Std())
+	}
+	if err := normalizeRetry(u); err != nil {
+		return err
+	}
+
+	if u.FailureThreshold == 0 {
+		u.FailureThreshold = model.DefaultThreshold
+	}
+	if u.RecoveryThreshold == 0 {
+		u.RecoveryThreshold = model.DefaultThreshold
+	}
+	if u.FailureThreshold < 0 || u.FailureThreshold > model.MaxThreshold ||
+		u.RecoveryThreshold < 0 || u.RecoveryThreshold > model.MaxThreshold {
+		return appErr.NewInvalid("thresholds must be between 1 and %d", model.MaxThreshold)
+	}
+	if u.FlapThreshold != 0 {
+		if u.FlapThreshold < 2 || u.FlapThreshold > model.MaxThreshold {
+			return appErr.NewInvalid("flap_threshold must be 0 (disabled) or between 2 and %d", model.MaxThreshold)
+		}
+		if u.FlapWindow == 0 {
+			u.FlapWindow = model.DefaultFlapWindow
+		}
+		if u.FlapWindow < u.Interval || u.FlapWindow > model.MaxFlapWindow {
+			return appErr.NewInvalid("flap_window must be between the check interval and %s", model.MaxFlapWindow.Std())
+		}
+	}
+	// State and the certificate seen are owned by the checker
+	u.State = model.CheckState{}
+	u.Cert = nil
+
+	return nil
+}
+
+// normalizeHTTPSpec validates the request an http monitor sends
+func normalizeHTTPSpec(u *model.URL) error {
+	parsed, err := url.Parse(u.Address)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return appErr.NewInvalid("address %q must be an absolute http(s) URL", u.Address)
+	}
+
+	u.Method = strings.ToUpper(strings.TrimSpace(u.Method))
+	if u.Method == "" {
+		u.Method = model.DefaultCheckMethod
+	}
+	if !allowedCheckMethods[u.Method] {
+		return appErr.NewInvalid("unsupported check method %q", u.Method)
+	}
+
+	if u.Body != "" && (u.Method == http.MethodGet || u.Method == http.MethodHead) {
+		return appErr.NewInvalid("request body is not allowed for %s checks", u.Method)
+	}
+
+	for name := range u.Headers {
+		if strings.TrimSpace(name) == "" {
+			return appErr.NewInvalid("header names must not be empty")
+		}
+	}
+	if _, err := assertion.Compile(u.Assertions); err != nil {
+		return appErr.NewInvalid("%v", err)
+	}
+	if u.Content != nil {
+		for _, pattern := range u.Content.Ignore {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return appErr.NewInvalid("content ignore pattern %q: %v", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// tcpAssertions are the assertions that make sense against a TCP response
+var tcpAssertions = map[string]bool{
+	model.AssertBodyContains: true,
+	model.AssertBodyRegex:    true,
+	model.AssertResponseTime: true,
+}
+
+// normalizeTCPSpec validates a host:port address and the optional exchange
+func normalizeTCPSpec(u *model.URL) error {
+	if err := validateHostPort(u.Address); err != nil {
+		return err
+	}
+	return validateAssertions(u, tcpAssertions)
+}
+
+// timingAssertions is all that applies to monitors whose probe itself is the
+// check, like a TLS handshake or a gRPC health call
+var timingAssertions = map[string]bool{
+	model.AssertResponseTime: true,
+}
+
+// normalizeTLSSpec validates a host:port address and the optional server name
+func normalizeTLSSpec(u *model.URL) error {
+	if err := validateHostPort(u.Address); err != nil {
+		return err
+	}
+	if u.TLS != nil {
+		u.TLS.ServerName = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(u.TLS.ServerName)), ".")
+		if u.TLS.ServerName != "" && !validDomainName(u.TLS.ServerName) {
+			return appErr.NewInvalid("tls server_name %q must be a domain name", u.TLS.ServerName)
+		}
+	}
+	return validateAssertions(u, timingAssertions)
+}
+
+// normalizeGRPCSpec validates a host:port address and the TLS server name
+func normalizeGRPCSpec(u *model.URL) error {
+	if err := validateHostPort(u.Address); err != nil {
+		return err
+	}
+	if u.GRPC != nil {
+		u.GRPC.Service = strings.TrimSpace(u.GRPC.Service)
+		u.GRPC.ServerName = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(u.GRPC.ServerName)), ".")
+		if u.GRPC.ServerName != "" && (!u.GRPC.TLS || !validDomainName(u.GRPC.ServerName)) {
+			return appErr.NewInvalid("grpc server_name must be a domain name and requires tls")
+		}
+	}
+	return validateAssertions(u, timingAssertions)
+}
+
+// noAssertions: heartbeat monitors have no response to assert on
+var noAssertions = map[string]bool{}
+
+// normalizeHeartbeatSpec issues the ping token and derives the address from it.
+// Interval is the period the job is expected to ping at.
+func normalizeHeartbeatSpec(u *model.URL) error {
+	if u.Heartbeat == nil {
+		u.Heartbeat = &model.HeartbeatCheck{}
+	}
+	if u.Heartbeat.Token == "" {
+		token, err := newHeartbeatToken()
+		if err != nil {
+			return appErr.NewInternal("failed to generate heartbeat token: %v", err)
+		}
+		u.Heartbeat.Token = token
+	}
+	u.Address = "/heartbeat/" + u.Heartbeat.Token
+
+	if u.Heartbeat.Grace == 0 {
+		u.Heartbeat.Grace = model.DefaultHeartbeatGrace
+	}
+	if u.Heartbeat.Grace < 0 || u.Heartbeat.Grace > model.MaxHeartbeatPeriod {
+		return appErr.NewInvalid("heartbeat grace must be between 0 and %s", model.MaxHeartbeatPeriod.Std())
+	}
+	return validateAssertions(u, noAssertions)
+}
+
+// newHeartbeatToken returns an unguessable token for a ping URL
+func newHeartbeatToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// checkTypeSettings rejects settings that belong to a different monitor type
+func checkTypeSettings(u *model.URL) error {
+	if u.Type != model.MonitorHTTP && (u.Method != "" || u.Body != "" || len(u.Headers) > 0) {
+		return appErr.NewInvalid("method, headers and body are only valid for http monitors")
+	}
+	if u.Transport != nil && u.Type != model.MonitorHTTP && u.Type != model.MonitorTransaction {
+		return appErr.NewInvalid("transport settings are only valid for http and transaction monitors")
+	}
+	settings := map[string]bool{
+		model.MonitorHTTP:        u.Content != nil || u.CredentialID != "",
+		model.MonitorTCP:         u.TCP != nil,
+		model.MonitorDNS:         u.DNS != nil,
+		model.MonitorTLS:         u.TLS != nil,
+		model.MonitorGRPC:        u.GRPC != nil,
+		model.MonitorHeartbeat:   u.Heartbeat != nil,
+		model.MonitorTransaction: u.Transaction != nil,
+	}
+	for monitorType, set := range settings {
+		if set && u.Type != monitorType {
+			return appErr.NewInvalid("%s settings are only valid for %s monitors", monitorType, monitorType)
+		}
+	}
+	return nil
+}
+
+// validateAssertions compiles the URL's assertions, allowing only the given types
+func validateAssertions(u *model.URL, allowed map[string]bool) error {
+	for _, a := range u.Assertions {
+		if !allowed[a.Type] {
+			return appErr.NewInvalid("assertion %q is not supported for %s monitors", a.Type, u.Type)
+		}
+	}
+	if _, err := assertion.Compile(u.Assertions); err != nil {
+		return appErr.NewInvalid("%v", err)
+	}
+	return nil
+}
+
+// validateHostPort checks address is host:port with a usable port
+func validateHostPort(address string) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || host == "" {
+		return appErr.NewInvalid("address %q must be host:port", address)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return appErr.NewInvalid("address %q has an invalid port", address)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/samims/hcaas/services/url/internal/diff"
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// GetContent returns the accepted content of a content monitor and the change
+// waiting to be accepted, with the diff between them
+func (s *urlService) GetContent(ctx context.Context, id string) (*model.ContentReview, error) {
+	url, err := s.findOwned(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if url.Content == nil {
+		return nil, appErr.NewInvalid("content change detection is not enabled for URL %s", id)
+	}
+
+	review := &model.ContentReview{Baseline: url.ContentBaseline, Changed: url.ContentChanged}
+	if url.ContentBaseline != nil && url.ContentChanged != nil {
+		review.Diff = diff.Unified("baseline", "changed", url.ContentBaseline.Body, url.ContentChanged.Body)
+	}
+	return review, nil
+}
+
+// AcceptContent makes the changed content the monitor's new baseline
+func (s *urlService) AcceptContent(ctx context.Context, id string) (*model.ContentSnapshot, error) {
+	url, err := s.findOwned(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if url.Content == nil {
+		return nil, appErr.NewInvalid("content change detection is not enabled for URL %s", id)
+	}
+
+	baseline, err := s.store.AcceptContent(ctx, id)
+	if err != nil {
+		if appErr.IsNotFound(err) {
+			return nil, appErr.NewConflict("URL %s has no content change to accept", id)
+		}
+		s.logger.Error("failed to accept content", slog.String("id", id), slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to accept content: %v", err)
+	}
+
+	s.logger.Info("Content baseline accepted", slog.String("id", id), slog.String("hash", baseline.Hash))
+	return &baseline, nil
+}
+
+// InitContentBaseline stores the first content the checker saw. Not user-scoped.
+func (s *urlService) InitContentBaseline(ctx context.Context, id string, snapshot model.ContentSnapshot) error {
+	if err := s.store.InitContentBaseline(ctx, id, snapshot); err != nil {
+		s.logger.Error("failed to init content baseline", slog.String("id", id), slog.String("error", err.Error()))
+		return appErr.NewInternal("failed to init content baseline: %v", err)
+	}
+	return nil
+}
+
+// SaveContentChanged stores content the checker found to differ from the
+// baseline, for review. Not user-scoped.
+func (s *urlService) SaveContentChanged(ctx context.Context, id string, snapshot model.ContentSnapshot) error {
+	if err := s.store.SaveContentChanged(ctx, id, snapshot); err != nil {
+		if appErr.IsNotFound(err) {
+			return appErr.NewNotFound("URL with ID %s not found", id)
+		}
+		s.logger.Error("failed to save changed content", slog.String("id", id), slog.String("error", err.Error()))
+		return appErr.NewInternal("failed to save changed content: %v", err)
+	}
+	return nil
+}
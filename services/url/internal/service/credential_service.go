@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+	"github.com/samims/hcaas/services/url/internal/secret"
+	"github.com/samims/hcaas/services/url/internal/storage"
+)
+
+// CredentialService manages the credentials monitors authenticate with.
+// Secrets go in on Create and only come back out through GetForCheck.
+type CredentialService interface {
+	Create(ctx context.Context, c model.Credential) (*model.Credential, error)
+	List(ctx context.Context) ([]model.Credential, error)
+	Delete(ctx context.Context, id string) error
+	GetForCheck(ctx context.Context, id string) (*model.Credential, error)
+}
+
+type credentialService struct {
+	store    storage.Storage
+	envelope *secret.Envelope
+	logger   *slog.Logger
+}
+
+func NewCredentialService(store storage.Storage, envelope *secret.Envelope, logger *slog.Logger) CredentialService {
+	l := logger.With("layer", "service", "component", "credentialService")
+	return &credentialService{store: store, envelope: envelope, logger: l}
+}
+
+func (s *credentialService) Create(ctx context.Context, c model.Credential) (*model.Credential, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.UserID = userID
+
+	if err := normalizeCredential(&c); err != nil {
+		return nil, err
+	}
+
+	sealed, err := s.envelope.Seal([]byte(c.Secret))
+	if err != nil {
+		if errors.Is(err, secret.ErrNoKey) {
+			return nil, appErr.NewInternal("credential storage is not configured: set CREDENTIALS_KEY")
+		}
+		s.logger.Error("failed to encrypt credential", slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to encrypt credential: %v", err)
+	}
+	c.Sealed = model.SealedSecret{WrappedKey: sealed.WrappedKey, Ciphertext: sealed.Ciphertext}
+	c.Secret = ""
+	c.ID = uuid.New().String()
+
+	if err := s.store.SaveCredential(ctx, &c); err != nil {
+		if errors.Is(err, appErr.ErrConflict) {
+			return nil, appErr.NewConflict("credential %q already exists", c.Name)
+		}
+		s.logger.Error("failed to save credential", slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to save credential: %v", err)
+	}
+
+	s.logger.Info("Credential created", slog.String("id", c.ID), slog.String("type", c.Type), slog.String("user_id", userID))
+	return &c, nil
+}
+
+func (s *credentialService) List(ctx context.Context) ([]model.Credential, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	credentials, err := s.store.FindCredentialsByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("failed to fetch credentials", slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to fetch credentials: %v", err)
+	}
+	return credentials, nil
+}
+
+// Delete removes an unused credential of the caller's
+func (s *credentialService) Delete(ctx context.Context, id string) error {
+	if _, err := findOwnedCredential(ctx, s.store, id); err != nil {
+		return err
+	}
+	if err := s.store.DeleteCredential(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, appErr.ErrConflict):
+			return appErr.NewConflict("credential %s is still used by monitors", id)
+		case errors.Is(err, appErr.ErrNotFound):
+			return appErr.NewNotFound("credential %s not found", id)
+		}
+		s.logger.Error("failed to delete credential", slog.String("id", id), slog.String("error", err.Error()))
+		return appErr.NewInternal("failed to delete credential: %v", err)
+	}
+	return nil
+}
+
+// GetForCheck returns the credential with its secret decrypted. Not user-scoped;
+// only the checker calls it.
+func (s *credentialService) GetForCheck(ctx context.Context, id string) (*model.Credential, error) {
+	c, err := s.store.FindCredentialByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, appErr.ErrNotFound) {
+			return nil, appErr.NewNotFound("credential %s not found", id)
+		}
+		return nil, appErr.NewInternal("failed to fetch credential: %v", err)
+	}
+	plaintext, err := s.envelope.Open(secret.Sealed{WrappedKey: c.Sealed.WrappedKey, Ciphertext: c.Sealed.Ciphertext})
+	if err != nil {
+		s.logger.Error("failed to decrypt credential", slog.String("id", id), slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to decrypt credential %s", id)
+	}
+	c.Secret = string(plaintext)
+	return &c, nil
+}
+
+// findOwnedCredential loads a credential and verifies it belongs to the user in
+// ctx. Other users' credentials are reported as not found.
+func findOwnedCredential(ctx context.Context, store storage.Storage, id string) (model.Credential, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return model.Credential{}, err
+	}
+	c, err := store.FindCredentialByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, appErr.ErrNotFound) {
+			return model.Credential{}, appErr.NewNotFound("credential %s not found", id)
+		}
+		return model.Credential{}, appErr.NewInternal("failed to fetch credential: %v", err)
+	}
+	if c.UserID != userID {
+		return model.Credential{}, appErr.NewNotFound("credential %s not found", id)
+	}
+	return c, nil
+}
+
+// normalizeCredential checks the fields each credential type needs and drops
+// the ones it doesn't use
+func normalizeCredential(c *model.Credential) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return appErr.NewInvalid("credential name must not be empty")
+	}
+	if c.Secret == "" {
+		return appErr.NewInvalid("credential secret must not be empty")
+	}
+
+	username, header, tokenURL, clientID, scopes := c.Username, strings.TrimSpace(c.Header), c.TokenURL, c.ClientID, c.Scopes
+	certificate := c.Certificate
+	c.Username, c.Header, c.TokenURL, c.ClientID, c.Scopes, c.Certificate = "", "", "", "", nil, ""
+
+	switch c.Type {
+	case model.CredentialBasic:
+		if username == "" {
+			return appErr.NewInvalid("basic credentials need a username")
+		}
+		c.Username = username
+	case model.CredentialBearer:
+	case model.CredentialAPIKey:
+		if header == "" {
+			header = model.DefaultAPIKeyHeader
+		}
+		c.Header = header
+	case model.CredentialOAuth2:
+		parsed, err := url.Parse(tokenURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return appErr.NewInvalid("token_url %q must be an absolute http(s) URL", tokenURL)
+		}
+		if clientID == "" {
+			return appErr.NewInvalid("oauth2 credentials need a client_id")
+		}
+		c.TokenURL, c.ClientID, c.Scopes = tokenURL, clientID, scopes
+	case model.CredentialClientCert:
+		if _, err := tls.X509KeyPair([]byte(certificate), []byte(c.Secret)); err != nil {
+			return appErr.NewInvalid("client_cert credentials need a PEM certificate and its private key as the secret: %v", err)
+		}
+		c.Certificate = certificate
+	default:
+		return appErr.NewInvalid("unsupported credential type %q", c.Type)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+var dnsRecordTypes = map[string]bool{
+	model.DNSRecordA:     true,
+	model.DNSRecordAAAA:  true,
+	model.DNSRecordCNAME: true,
+	model.DNSRecordMX:    true,
+	model.DNSRecordTXT:   true,
+}
+
+// normalizeDNSSpec validates the name, record type, resolver and expected answers
+// of a dns monitor
+func normalizeDNSSpec(u *model.URL) error {
+	u.Address = canonicalAddress(model.MonitorDNS, u.Address)
+	if !validDomainName(u.Address) {
+		return appErr.NewInvalid("address %q must be a domain name", u.Address)
+	}
+
+	if u.DNS == nil {
+		u.DNS = &model.DNSCheck{}
+	}
+	spec := u.DNS
+
+	spec.RecordType = strings.ToUpper(strings.TrimSpace(spec.RecordType))
+	if spec.RecordType == "" {
+		spec.RecordType = model.DNSRecordA
+	}
+	if !dnsRecordTypes[spec.RecordType] {
+		return appErr.NewInvalid("unsupported dns record type %q", spec.RecordType)
+	}
+
+	if spec.Resolver != "" {
+		// A bare IP means the standard DNS port
+		if ip, err := netip.ParseAddr(spec.Resolver); err == nil {
+			spec.Resolver = net.JoinHostPort(ip.String(), "53")
+		}
+		if err := validateHostPort(spec.Resolver); err != nil {
+			return appErr.NewInvalid("resolver %q must be an IP or host:port", spec.Resolver)
+		}
+	}
+
+	for i, value := range spec.Expected {
+		normalized, err := normalizeDNSAnswer(spec.RecordType, value)
+		if err != nil {
+			return err
+		}
+		spec.Expected[i] = normalized
+	}
+
+	// Answers are checked through DNSCheck.Expected
+	return validateAssertions(u, timingAssertions)
+}
+
+// normalizeDNSAnswer puts an expected answer in the form the checker compares against
+func normalizeDNSAnswer(recordType, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch recordType {
+	case model.DNSRecordA, model.DNSRecordAAAA:
+		ip, err := netip.ParseAddr(value)
+		if err != nil || ip.Is4() != (recordType == model.DNSRecordA) {
+			return "", appErr.NewInvalid("expected %s answer %q is not a valid address", recordType, value)
+		}
+		return ip.String(), nil
+	case model.DNSRecordCNAME:
+		name := strings.TrimSuffix(strings.ToLower(value), ".")
+		if !validDomainName(name) {
+			return "", appErr.NewInvalid("expected CNAME answer %q is not a domain name", value)
+		}
+		return name, nil
+	case model.DNSRecordMX:
+		// Formatted like the checker's answers: decimal preference, lowercase host without the root dot
+		fields := strings.Fields(value)
+		if len(fields) != 2 {
+			return "", appErr.NewInvalid("expected MX answer %q must be \"<preference> <host>\"", value)
+		}
+		pref, err := strconv.ParseUint(fields[0], 10, 16)
+		host := strings.TrimSuffix(strings.ToLower(fields[1]), ".")
+		if err != nil || !validDomainName(host) {
+			return "", appErr.NewInvalid("expected MX answer %q must be \"<preference> <host>\"", value)
+		}
+		return strconv.FormatUint(pref, 10) + " " + host, nil
+	}
+	return value, nil
+}
+
+// validDomainName does a light syntax check: dot-separated labels of up to 63
+// letters, digits, hyphens or underscores
+func validDomainName(name string) bool {
+	if name == "" || len(name) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
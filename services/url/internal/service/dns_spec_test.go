@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// Test_normalizeDNSAnswer tests expected answers are put in the checker's form.
+// Table Driven Test Pattern used
+func Test_normalizeDNSAnswer(t *testing.T) {
+	tests := []struct {
+		name       string
+		recordType string
+		value      string
+		want       string
+		wantErr    bool
+	}{
+		{name: "A", recordType: model.DNSRecordA, value: " 10.0.0.1 ", want: "10.0.0.1"},
+		{name: "AAAA for A", recordType: model.DNSRecordA, value: "2001:db8::1", wantErr: true},
+		{name: "CNAME root dot", recordType: model.DNSRecordCNAME, value: "App.Example.com.", want: "app.example.com"},
+		{name: "MX", recordType: model.DNSRecordMX, value: "10 mail.example.com", want: "10 mail.example.com"},
+		{name: "MX leading zeros", recordType: model.DNSRecordMX, value: "010 mail.example.com", want: "10 mail.example.com"},
+		{name: "MX host case and root dot", recordType: model.DNSRecordMX, value: "10\tMail.Example.com.", want: "10 mail.example.com"},
+		{name: "MX preference out of range", recordType: model.DNSRecordMX, value: "65536 mail.example.com", wantErr: true},
+		{name: "MX without host", recordType: model.DNSRecordMX, value: "10", wantErr: true},
+		{name: "MX extra field", recordType: model.DNSRecordMX, value: "10 mail.example.com extra", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeDNSAnswer(tt.recordType, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeDNSAnswer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeDNSAnswer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
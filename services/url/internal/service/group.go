@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// ListGroups returns the user's groups with their aggregate status
+func (s *urlService) ListGroups(ctx context.Context) ([]model.GroupSummary, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.store.FindGroups(ctx, userID)
+	if err != nil {
+		s.logger.Error("failed to list groups", slog.String("user_id", userID), slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to list groups: %v", err)
+	}
+	for i := range groups {
+		groups[i].Status = groupStatus(groups[i])
+	}
+	return groups, nil
+}
+
+// GetGroup returns one of the user's groups with its aggregate status
+func (s *urlService) GetGroup(ctx context.Context, name string) (*model.GroupSummary, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !validGroup(name) {
+		return nil, appErr.NewNotFound("group %s not found", name)
+	}
+
+	group, err := s.store.FindGroup(ctx, userID, name)
+	if err != nil {
+		if errors.Is(err, appErr.ErrNotFound) {
+			return nil, appErr.NewNotFound("group %s not found", name)
+		}
+		s.logger.Error("failed to fetch group", slog.String("group", name), slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to fetch group: %v", err)
+	}
+	group.Status = groupStatus(group)
+	return &group, nil
+}
+
+// SetGroupPaused pauses or resumes every monitor in one of the user's groups
+func (s *urlService) SetGroupPaused(ctx context.Context, name string, paused bool) (*model.GroupSummary, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.GetGroup(ctx, name); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.SetGroupPaused(ctx, userID, name, paused); err != nil {
+		s.logger.Error("failed to set group paused", slog.String("group", name), slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to set group paused: %v", err)
+	}
+	s.logger.Info("SetGroupPaused succeeded", slog.String("group", name), slog.Bool("paused", paused))
+	return s.GetGroup(ctx, name)
+}
+
+// groupStatus is the worst status among a group's monitors being checked:
+// unhealthy, then flapping, then healthy. A group with every monitor paused is
+// paused, and one with none checked yet is unknown.
+func groupStatus(g model.GroupSummary) string {
+	switch {
+	case g.Total > 0 && g.Paused == g.Total:
+		return model.StatusPaused
+	case g.Unhealthy > 0:
+		return model.StatusUnhealthy
+	case g.Flapping > 0:
+		return model.StatusFlapping
+	case g.Healthy > 0:
+		return model.StatusHealthy
+	default:
+		return model.StatusUnknown
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// Test_groupStatus tests how a group's status is aggregated from its monitors.
+// Table Driven Test Pattern used
+func Test_groupStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		group model.GroupSummary
+		want  string
+	}{
+		{
+			name:  "all healthy",
+			group: model.GroupSummary{Total: 3, Healthy: 3},
+			want:  model.StatusHealthy,
+		},
+		{
+			name:  "one unhealthy wins over flapping",
+			group: model.GroupSummary{Total: 3, Healthy: 1, Unhealthy: 1, Flapping: 1},
+			want:  model.StatusUnhealthy,
+		},
+		{
+			name:  "flapping",
+			group: model.GroupSummary{Total: 2, Healthy: 1, Flapping: 1},
+			want:  model.StatusFlapping,
+		},
+		{
+			name:  "unchecked monitors don't hide healthy ones",
+			group: model.GroupSummary{Total: 2, Healthy: 1, Unknown: 1},
+			want:  model.StatusHealthy,
+		},
+		{
+			name:  "none checked yet",
+			group: model.GroupSummary{Total: 2, Unknown: 2},
+			want:  model.StatusUnknown,
+		},
+		{
+			name:  "paused monitors are left out",
+			group: model.GroupSummary{Total: 2, Healthy: 1, Paused: 1},
+			want:  model.StatusHealthy,
+		},
+		{
+			name:  "all paused",
+			group: model.GroupSummary{Total: 2, Paused: 2},
+			want:  model.StatusPaused,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := groupStatus(tt.group); got != tt.want {
+				t.Errorf("groupStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// GetByHeartbeatToken finds the heartbeat monitor a ping URL belongs to.
+// Pings are unauthenticated, so the token is the only credential.
+func (s *urlService) GetByHeartbeatToken(ctx context.Context, token string) (*model.URL, error) {
+	url, err := s.store.FindByHeartbeatToken(ctx, token)
+	if err != nil {
+		if appErr.IsNotFound(err) {
+			return nil, appErr.NewNotFound("heartbeat not found")
+		}
+		s.logger.Error("failed to fetch heartbeat", slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to fetch heartbeat: %v", err)
+	}
+	return &url, nil
+}
+
+// MarkRunStarted remembers that a heartbeat monitor's job signalled its start.
+// Not user-scoped; heartbeat pings call it.
+func (s *urlService) MarkRunStarted(ctx context.Context, id string, at time.Time) error {
+	if err := s.store.MarkRunStarted(ctx, id, at); err != nil {
+		if appErr.IsNotFound(err) {
+			return appErr.NewNotFound("URL with ID %s not found", id)
+		}
+		s.logger.Error("failed to mark run started", slog.String("id", id), slog.String("error", err.Error()))
+		return appErr.NewInternal("failed to mark run started: %v", err)
+	}
+	return nil
+}
+
+// RecordHeartbeat stores a ping or missed heartbeat like RecordCheck, provided
+// the monitor is still as loaded; a conflict means another ping or check was
+// recorded first. Not user-scoped; the heartbeat receiver and checker call it.
+func (s *urlService) RecordHeartbeat(ctx context.Context, result *model.CheckResult, status string, state model.CheckState, loaded model.URL) error {
+	if err := s.store.RecordHeartbeat(ctx, result, status, state, loaded); err != nil {
+		switch {
+		case appErr.IsNotFound(err):
+			return appErr.NewNotFound("URL with ID %s not found", result.URLID)
+		case appErr.IsConflict(err):
+			return appErr.NewConflict("URL with ID %s changed since it was loaded", result.URLID)
+		}
+		s.logger.Error("failed to record heartbeat",
+			slog.String("id", result.URLID),
+			slog.String("error", err.Error()))
+		return appErr.NewInternal("failed to record heartbeat: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/samims/hcaas/services/url/internal/bulk"
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// maxImportRows caps the monitors one import may hold
+const maxImportRows = 1000
+
+// Import creates or updates the user's monitors from rows, one JSON object
+// per monitor as POST /urls takes it. A row updates the live monitor with
+// the same address, changing only the fields it has, and is skipped when
+// that changes nothing; changing a monitor sync manages flags it as drifted.
+// Every row is validated; when any is invalid, nothing is written and the
+// report comes back with an Invalid error. Otherwise, all rows are written in
+// one transaction, unless dryRun.
+func (s *urlService) Import(ctx context.Context, rows []json.RawMessage, dryRun bool) (*model.ImportReport, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > maxImportRows {
+		return nil, appErr.NewInvalid("an import can have at most %d monitors", maxImportRows)
+	}
+
+	existing, err := s.store.FindAllByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("failed to fetch URLs", slog.String("user_id", userID), slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to fetch URLs: %v", err)
+	}
+	byAddress := make(map[string]model.URL, len(existing))
+	for _, url := range existing {
+		byAddress[url.Address] = url
+	}
+
+	report := &model.ImportReport{DryRun: dryRun, Rows: make([]model.ImportRow, len(rows))}
+	var creates, updates []model.URL
+	seen := map[string]int{}
+	for i, raw := range rows {
+		row := &report.Rows[i]
+		row.Row = i + 1
+
+		url, action, err := s.importRow(ctx, userID, raw, byAddress)
+		row.Address = url.Address
+		// Addresses are compared as stored, after the row was normalized
+		if err == nil {
+			if first, ok := seen[url.Address]; ok {
+				err = appErr.NewInvalid("address is also in row %d", first)
+			} else if other, ok := byAddress[url.Address]; ok && other.ID != url.ID {
+				err = appErr.NewInvalid("address is used by monitor %s", other.ID)
+			}
+			seen[url.Address] = row.Row
+		}
+		if err != nil {
+			if !appErr.IsInvalid(err) && !appErr.IsNotFound(err) {
+				return nil, err
+			}
+			row.Action, row.Error = model.ImportInvalid, err.Error()
+			report.Invalid++
+			continue
+		}
+
+		row.Action, row.ID = action, url.ID
+		switch action {
+		case model.ImportCreate:
+			creates = append(creates, url)
+			report.Created++
+		case model.ImportUpdate:
+			updates = append(updates, url)
+			report.Updated++
+		default:
+			report.Skipped++
+		}
+	}
+
+	if report.Invalid > 0 {
+		return report, appErr.NewInvalid("%d of %d rows are invalid", report.Invalid, len(rows))
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	if err := s.store.ApplyURLs(ctx, creates, updates, nil); err != nil {
+		switch {
+		case errors.Is(err, appErr.ErrConflict):
+			return nil, appErr.NewConflict("a monitor in the import already exists")
+		case errors.Is(err, appErr.ErrNotFound):
+			return nil, appErr.NewConflict("a monitor in the import was deleted meanwhile")
+		}
+		s.logger.Error("failed to import URLs", slog.String("user_id", userID), slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to import URLs: %v", err)
+	}
+
+	s.logger.Info("Import succeeded",
+		slog.String("user_id", userID),
+		slog.Int("created", report.Created),
+		slog.Int("updated", report.Updated),
+		slog.Int("skipped", report.Skipped))
+	return report, nil
+}
+
+// importRow decodes and validates one row, onto the live monitor with its
+// address when there is one, and says what importing it would do
+func (s *urlService) importRow(ctx context.Context, userID string, raw json.RawMessage, byAddress map[string]model.URL) (model.URL, string, error) {
+	raw, err := bulk.StripRuntime(raw)
+	if err != nil {
+		return model.URL{}, "", appErr.NewInvalid("invalid monitor: %v", err)
+	}
+	var key struct {
+		Type    string `json:"type"`
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(raw, &key); err != nil {
+		return model.URL{}, "", appErr.NewInvalid("invalid monitor: %v", err)
+	}
+	key.Address = canonicalAddress(key.Type, key.Address)
+
+	existing, found := byAddress[key.Address]
+	var url model.URL
+	if found {
+		// Decode onto a copy that shares nothing with existing, so the
+		// comparison below sees the changes
+		if err := copyURL(&url, existing); err != nil {
+			return url, "", appErr.NewInternal("failed to copy URL: %v", err)
+		}
+	}
+	if err := json.Unmarshal(raw, &url); err != nil {
+		return model.URL{Address: key.Address}, "", appErr.NewInvalid("invalid monitor: %v", err)
+	}
+	url.Address = key.Address
+
+	// Tokens are always issued by the server; an updated heartbeat monitor keeps its own
+	if url.Heartbeat != nil {
+		url.Heartbeat.Token = ""
+		if found && existing.Heartbeat != nil {
+			url.Heartbeat.Token = existing.Heartbeat.Token
+		}
+	}
+	if found {
+		url.ID, url.UserID = existing.ID, existing.UserID
+	} else {
+		url.ID, url.UserID = uuid.New().String(), userID
+		url.Managed, url.Drifted = false, false
+	}
+
+	if err := normalizeCheckSpec(&url); err != nil {
+		return url, "", err
+	}
+	if err := normalizeMetadata(&url); err != nil {
+		return url, "", err
+	}
+	if err := s.checkCredentials(ctx, url); err != nil {
+		return url, "", err
+	}
+
+	if !found {
+		if url.Type == model.MonitorHeartbeat {
+			url.CheckedAt = time.Now()
+		}
+		return url, model.ImportCreate, nil
+	}
+	if err := markManualEdit(&url, existing); err != nil {
+		return url, "", appErr.NewInternal("failed to compare monitors: %v", err)
+	}
+	fields, err := changedFields(url, existing)
+	if err != nil {
+		return url, "", appErr.NewInternal("failed to compare monitors: %v", err)
+	}
+	if len(fields) == 0 {
+		return url, model.ImportSkip, nil
+	}
+	return url, model.ImportUpdate, nil
+}
+
+// copyURL deep-copies src into dst
+func copyURL(dst *model.URL, src model.URL) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+	"github.com/samims/hcaas/services/url/internal/storage"
+)
+
+// listStorage serves a fixed set of the user's monitors; methods the tests
+// don't use panic through the embedded nil interface
+type listStorage struct {
+	storage.Storage
+	urls []model.URL
+}
+
+func (l *listStorage) FindAllByUserID(context.Context, string) ([]model.URL, error) {
+	return l.urls, nil
+}
+
+// Test_urlService_Import tests how import rows are matched with stored monitors.
+// Table Driven Test Pattern used
+func Test_urlService_Import(t *testing.T) {
+	stored := []model.URL{
+		{
+			ID: "dns-1", UserID: "user-1", Type: model.MonitorDNS, Address: "example.com",
+			DNS:      &model.DNSCheck{RecordType: model.DNSRecordA},
+			Interval: model.DefaultCheckInterval, Timeout: model.DefaultCheckTimeout,
+		},
+		{
+			ID: "http-1", UserID: "user-1", Type: model.MonitorHTTP, Address: "https://shop.example.com/health",
+			Method: "GET", Interval: model.DefaultCheckInterval, Timeout: model.DefaultCheckTimeout,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		rows        []string
+		wantActions []string
+		wantID      string // of the first row
+		wantError   string // of the last row
+	}{
+		{
+			name:        "dns name matched as stored",
+			rows:        []string{`{"type": "dns", "address": "Example.COM.", "interval": "30s"}`},
+			wantActions: []string{model.ImportUpdate},
+			wantID:      "dns-1",
+		},
+		{
+			name:        "same dns name twice",
+			rows:        []string{`{"type": "dns", "address": "api.example.com"}`, `{"type": "dns", "address": "API.example.com."}`},
+			wantActions: []string{model.ImportCreate, model.ImportInvalid},
+			wantError:   "also in row 1",
+		},
+		{
+			name:        "create whose normalized address is taken",
+			rows:        []string{`{"type": "transaction", "transaction": {"steps": [{"method": "GET", "url": "https://shop.example.com/health"}]}}`},
+			wantActions: []string{model.ImportInvalid},
+			wantError:   "used by monitor http-1",
+		},
+		{
+			name:        "new monitor",
+			rows:        []string{`{"address": "https://shop.example.com/cart"}`},
+			wantActions: []string{model.ImportCreate},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &urlService{store: &listStorage{urls: stored}, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+			ctx := context.WithValue(context.Background(), model.ContextUserIDKey, "user-1")
+			var rows []json.RawMessage
+			for _, row := range tt.rows {
+				rows = append(rows, json.RawMessage(row))
+			}
+
+			report, _ := s.Import(ctx, rows, true)
+			if report == nil {
+				t.Fatal("Import() report = nil")
+			}
+			var actions []string
+			for _, row := range report.Rows {
+				actions = append(actions, row.Action)
+			}
+			if strings.Join(actions, ",") != strings.Join(tt.wantActions, ",") {
+				t.Fatalf("actions = %v, want %v (%+v)", actions, tt.wantActions, report.Rows)
+			}
+			if tt.wantID != "" && report.Rows[0].ID != tt.wantID {
+				t.Errorf("ID = %q, want %q", report.Rows[0].ID, tt.wantID)
+			}
+			if last := report.Rows[len(report.Rows)-1]; !strings.Contains(last.Error, tt.wantError) {
+				t.Errorf("Error = %q, want it to mention %q", last.Error, tt.wantError)
+			}
+		})
+	}
+}
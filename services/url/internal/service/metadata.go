@@ -0,0 +1,73 @@
+package service
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/labels"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// Limits on the descriptive fields of a monitor
+const (
+	maxNameLength = 200
+	maxTags       = 20
+	maxTagLength  = 64
+)
+
+var (
+	// groupPattern is what a group name may look like; names appear in /groups/{name} paths
+	groupPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?$`)
+	// keyPattern is what the external key sync matches monitors by may look like
+	keyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,126}[A-Za-z0-9])?$`)
+)
+
+// normalizeMetadata trims the name, tags, group and key, dropping empty and
+// duplicate tags, and validates the labels
+func normalizeMetadata(u *model.URL) error {
+	u.Name = strings.TrimSpace(u.Name)
+	if len(u.Name) > maxNameLength {
+		return appErr.NewInvalid("name must be at most %d characters", maxNameLength)
+	}
+
+	tags := make([]string, 0, len(u.Tags))
+	for _, tag := range u.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || slices.Contains(tags, tag) {
+			continue
+		}
+		if len(tag) > maxTagLength {
+			return appErr.NewInvalid("tag %q must be at most %d characters", tag, maxTagLength)
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTags {
+		return appErr.NewInvalid("a monitor can have at most %d tags", maxTags)
+	}
+	u.Tags = tags
+
+	if err := labels.Validate(u.Labels); err != nil {
+		return appErr.NewInvalid("%v", err)
+	}
+	if u.Labels == nil {
+		u.Labels = map[string]string{}
+	}
+
+	u.Group = strings.TrimSpace(u.Group)
+	if u.Group != "" && !groupPattern.MatchString(u.Group) {
+		return appErr.NewInvalid("invalid group %q: use up to 63 letters, digits, '.', '_' or '-'", u.Group)
+	}
+
+	u.Key = strings.TrimSpace(u.Key)
+	if u.Key != "" && !keyPattern.MatchString(u.Key) {
+		return appErr.NewInvalid("invalid key %q: use up to 128 letters, digits, '.', '_', '-' or '/'", u.Key)
+	}
+	return nil
+}
+
+// validGroup reports whether name is a group name normalizeMetadata accepts
+func validGroup(name string) bool {
+	return groupPattern.MatchString(name)
+}
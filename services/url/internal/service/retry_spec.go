@@ -0,0 +1,61 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// normalizeRetry validates the retry policy and makes sure every attempt and
+// the waits between them fit in one check interval. Timeout and interval must
+// already have their defaults.
+func normalizeRetry(u *model.URL) error {
+	r := u.Retry
+	if r == nil {
+		return nil
+	}
+	if u.Type == model.MonitorHeartbeat {
+		return appErr.NewInvalid("retry is not supported for heartbeat monitors")
+	}
+	if r.Count < 1 || r.Count > model.MaxRetries {
+		return appErr.NewInvalid("retry count must be between 1 and %d", model.MaxRetries)
+	}
+	if r.Backoff == 0 {
+		r.Backoff = model.DefaultRetryBackoff
+	}
+	if r.Backoff < 0 || r.Backoff > model.MaxRetryBackoff {
+		return appErr.NewInvalid("retry backoff must be between 0 and %s", model.MaxRetryBackoff.Std())
+	}
+
+	classes := make([]string, 0, len(r.ErrorClasses))
+	for _, class := range r.ErrorClasses {
+		class = strings.ToLower(strings.TrimSpace(class))
+		if !model.RetryableErrorClasses[class] {
+			return appErr.NewInvalid("error class %q can't be retried", class)
+		}
+		if !slices.Contains(classes, class) {
+			classes = append(classes, class)
+		}
+	}
+	r.ErrorClasses = classes
+	for _, code := range r.StatusCodes {
+		if code < 100 || code > 599 {
+			return appErr.NewInvalid("retry status code %d is not an HTTP status", code)
+		}
+	}
+	if len(r.ErrorClasses) == 0 && len(r.StatusCodes) == 0 {
+		r.ErrorClasses = slices.Clone(model.DefaultRetryErrorClasses)
+	}
+
+	worst := model.Duration(r.Count+1) * u.Timeout
+	for i := 0; i < r.Count; i++ {
+		worst += model.RetryDelay(r, i)
+	}
+	if worst > u.Interval {
+		return appErr.NewInvalid("%d attempts with their backoff can take %s, longer than the interval %s",
+			r.Count+1, worst.Std(), u.Interval.Std())
+	}
+	return nil
+}
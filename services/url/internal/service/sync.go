@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/samims/hcaas/services/url/internal/bulk"
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// plannedURL is a monitor a sync writes, with the index of its change in the plan
+type plannedURL struct {
+	url    model.URL
+	change int
+}
+
+// Sync makes the user's managed monitors, or those in doc.Group, match doc.
+// Monitors are matched by key: missing ones are created, and managed ones not
+// in doc are deleted. A group's sync rejects keys of managed monitors outside
+// the group. A monitor with a new key takes over an unmanaged one
+// with the same address. Every monitor sync writes is marked managed; manual
+// edits since the last sync show as drifted and are overwritten. When any
+// monitor is invalid, nothing is written and the plan comes back with an
+// Invalid error. Otherwise, all changes are applied in one transaction, unless
+// dryRun.
+func (s *urlService) Sync(ctx context.Context, doc model.SyncDocument, dryRun bool) (*model.SyncPlan, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Group != "" && !validGroup(doc.Group) {
+		return nil, appErr.NewInvalid("invalid group %q", doc.Group)
+	}
+	if doc.Monitors == nil {
+		return nil, appErr.NewInvalid("the document has no monitors list; send an empty list to delete every managed monitor")
+	}
+	if len(doc.Monitors) > maxImportRows {
+		return nil, appErr.NewInvalid("a sync can have at most %d monitors", maxImportRows)
+	}
+
+	existing, err := s.store.FindAllByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("failed to fetch URLs", slog.String("user_id", userID), slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to fetch URLs: %v", err)
+	}
+	byKey := map[string]model.URL{}
+	byAddress := map[string]model.URL{}
+	for _, url := range existing {
+		if url.Key != "" {
+			byKey[url.Key] = url
+		}
+		byAddress[url.Address] = url
+	}
+
+	plan := &model.SyncPlan{DryRun: dryRun, Group: doc.Group, Changes: []model.SyncChange{}}
+	var planned []plannedURL
+	desired := map[string]int{}
+	for i, raw := range doc.Monitors {
+		url, change, err := s.syncMonitor(ctx, userID, doc.Group, raw, byKey, byAddress)
+		// Invalid monitors are desired too, so the plan doesn't show them deleted
+		if n, ok := desired[change.Key]; ok && err == nil {
+			err = appErr.NewInvalid("key is also used by monitor %d", n)
+		} else if !ok && change.Key != "" {
+			desired[change.Key] = i + 1
+		}
+		if err != nil {
+			if !appErr.IsInvalid(err) && !appErr.IsNotFound(err) {
+				return nil, err
+			}
+			change.Action, change.Error = model.SyncInvalid, err.Error()
+		}
+		plan.Changes = append(plan.Changes, change)
+		if change.Action != model.SyncInvalid && change.Action != model.SyncUnchanged {
+			planned = append(planned, plannedURL{url: url, change: len(plan.Changes) - 1})
+		}
+	}
+
+	// Managed monitors in scope that the document no longer has
+	var deletes []string
+	deleted := map[string]bool{}
+	for _, url := range existing {
+		if !url.Managed || (doc.Group != "" && url.Group != doc.Group) {
+			continue
+		}
+		if _, ok := desired[url.Key]; ok {
+			continue
+		}
+		deletes = append(deletes, url.ID)
+		deleted[url.ID] = true
+		plan.Changes = append(plan.Changes, model.SyncChange{
+			Key: url.Key, Action: model.SyncDelete, ID: url.ID, Address: url.Address, Drifted: url.Drifted,
+		})
+	}
+
+	// Addresses stay unique among the live monitors once the plan is applied
+	addresses := map[string]string{}
+	for _, url := range existing {
+		if !deleted[url.ID] {
+			addresses[url.ID] = url.Address
+		}
+	}
+	for _, p := range planned {
+		addresses[p.url.ID] = p.url.Address
+	}
+	owners := map[string]int{}
+	for _, address := range addresses {
+		owners[address]++
+	}
+	var creates, updates []model.URL
+	for _, p := range planned {
+		change := &plan.Changes[p.change]
+		if owners[p.url.Address] > 1 {
+			change.Action, change.Error = model.SyncInvalid, "address "+p.url.Address+" is used by another monitor"
+			continue
+		}
+		if change.Action == model.SyncCreate {
+			creates = append(creates, p.url)
+		} else {
+			updates = append(updates, p.url)
+		}
+	}
+
+	for _, change := range plan.Changes {
+		switch change.Action {
+		case model.SyncCreate:
+			plan.Created++
+		case model.SyncUpdate, model.SyncAdopt:
+			plan.Updated++
+		case model.SyncDelete:
+			plan.Deleted++
+		case model.SyncUnchanged:
+			plan.Unchanged++
+		default:
+			plan.Invalid++
+		}
+	}
+	if plan.Invalid > 0 {
+		return plan, appErr.NewInvalid("%d of %d monitors are invalid", plan.Invalid, len(doc.Monitors))
+	}
+	if dryRun {
+		return plan, nil
+	}
+
+	if err := s.store.ApplyURLs(ctx, creates, updates, deletes); err != nil {
+		if errors.Is(err, appErr.ErrConflict) || errors.Is(err, appErr.ErrNotFound) {
+			return nil, appErr.NewConflict("monitors changed during the sync; run it again")
+		}
+		s.logger.Error("failed to sync URLs", slog.String("user_id", userID), slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to sync URLs: %v", err)
+	}
+
+	s.logger.Info("Sync succeeded",
+		slog.String("user_id", userID),
+		slog.String("group", doc.Group),
+		slog.Int("created", plan.Created),
+		slog.Int("updated", plan.Updated),
+		slog.Int("deleted", plan.Deleted))
+	return plan, nil
+}
+
+// syncMonitor decodes and validates one desired monitor, matches it with a
+// live one and plans the change. The monitor replaces the matched one whole:
+// fields it leaves out get their defaults.
+func (s *urlService) syncMonitor(
+	ctx context.Context,
+	userID, group string,
+	raw json.RawMessage,
+	byKey, byAddress map[string]model.URL,
+) (model.URL, model.SyncChange, error) {
+	var url model.URL
+	var change model.SyncChange
+	raw, err := bulk.StripRuntime(raw)
+	if err != nil {
+		return url, change, appErr.NewInvalid("invalid monitor: %v", err)
+	}
+	if err := json.Unmarshal(raw, &url); err != nil {
+		return url, change, appErr.NewInvalid("invalid monitor: %v", err)
+	}
+	url.Key, url.Address = strings.TrimSpace(url.Key), canonicalAddress(url.Type, url.Address)
+	change.Key, change.Address = url.Key, url.Address
+	if url.Key == "" {
+		return url, change, appErr.NewInvalid("key is required")
+	}
+	if group != "" {
+		if url.Group != "" && url.Group != group {
+			return url, change, appErr.NewInvalid("group %q is not the document's %q", url.Group, group)
+		}
+		url.Group = group
+	}
+
+	change.Action = model.SyncCreate
+	existing, found := byKey[url.Key]
+	// Keys are unique per user, but a group's sync only owns its own group:
+	// taking over another sync's monitor would move it back and forth
+	if found && group != "" && existing.Managed && existing.Group != group {
+		return url, change, appErr.NewInvalid("key is used by a managed monitor outside group %q", group)
+	}
+	if !found {
+		if other, ok := byAddress[url.Address]; ok && !other.Managed && other.Key == "" {
+			existing, found = other, true
+		}
+	}
+	if found {
+		change.ID, change.Drifted = existing.ID, existing.Drifted
+		change.Action = model.SyncUpdate
+		if !existing.Managed {
+			change.Action = model.SyncAdopt
+		}
+		url.ID, url.UserID = existing.ID, existing.UserID
+	} else {
+		url.ID, url.UserID = uuid.New().String(), userID
+	}
+	// Tokens are always issued by the server; an updated heartbeat monitor keeps its own
+	if url.Heartbeat != nil {
+		url.Heartbeat.Token = ""
+		if found && existing.Heartbeat != nil {
+			url.Heartbeat.Token = existing.Heartbeat.Token
+		}
+	}
+	url.Managed, url.Drifted = true, false
+
+	if err := normalizeCheckSpec(&url); err != nil {
+		return url, change, err
+	}
+	if err := normalizeMetadata(&url); err != nil {
+		return url, change, err
+	}
+	if err := s.checkCredentials(ctx, url); err != nil {
+		return url, change, err
+	}
+	change.Address = url.Address
+
+	if !found {
+		if url.Type == model.MonitorHeartbeat {
+			url.CheckedAt = time.Now()
+		}
+		return url, change, nil
+	}
+	fields, err := changedFields(url, existing)
+	if err != nil {
+		return url, change, appErr.NewInternal("failed to compare monitors: %v", err)
+	}
+	change.Fields = fields
+	if len(fields) == 0 && existing.Managed && !existing.Drifted {
+		change.Action = model.SyncUnchanged
+	}
+	return url, change, nil
+}
+
+// markManualEdit keeps the sync fields of existing on url, an edit made
+// outside sync, and flags a managed monitor whose spec the edit changes as
+// drifted
+func markManualEdit(url *model.URL, existing model.URL) error {
+	url.Managed, url.Drifted = existing.Managed, existing.Drifted
+	if !existing.Managed {
+		return nil
+	}
+	url.Key = existing.Key
+	if url.Drifted {
+		return nil
+	}
+	fields, err := changedFields(*url, existing)
+	if err != nil {
+		return err
+	}
+	url.Drifted = len(fields) > 0
+	return nil
+}
+
+// changedFields lists the importable fields that differ between two monitors
+func changedFields(a, b model.URL) ([]string, error) {
+	specA, err := bulk.Spec(a)
+	if err != nil {
+		return nil, err
+	}
+	specB, err := bulk.Spec(b)
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	for field, value := range specA {
+		if !reflect.DeepEqual(value, specB[field]) {
+			fields = append(fields, field)
+		}
+	}
+	for field := range specB {
+		if _, ok := specA[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+	return fields, nil
+}
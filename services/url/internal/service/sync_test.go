@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// Test_markManualEdit tests how edits outside sync flag managed monitors.
+// Table Driven Test Pattern used
+func Test_markManualEdit(t *testing.T) {
+	existing := model.URL{
+		ID:       "id-1",
+		Key:      "checkout",
+		Address:  "https://pay.example.com/health",
+		Interval: model.Duration(time.Minute),
+		Managed:  true,
+	}
+
+	tests := []struct {
+		name        string
+		existing    func(u *model.URL)
+		edit        func(u *model.URL)
+		wantDrifted bool
+		wantFields  []string
+	}{
+		{
+			name:        "managed monitor edited",
+			edit:        func(u *model.URL) { u.Interval = model.Duration(30 * time.Second) },
+			wantDrifted: true,
+			wantFields:  []string{"interval"},
+		},
+		{
+			name:        "managed monitor saved unchanged",
+			edit:        func(u *model.URL) {},
+			wantDrifted: false,
+		},
+		{
+			name:        "key of a managed monitor is kept",
+			edit:        func(u *model.URL) { u.Key = "renamed" },
+			wantDrifted: false,
+		},
+		{
+			name:        "status changes are not edits",
+			edit:        func(u *model.URL) { u.Status = model.StatusDown; u.Paused = true },
+			wantDrifted: false,
+		},
+		{
+			name:        "drifted stays drifted",
+			existing:    func(u *model.URL) { u.Drifted = true },
+			edit:        func(u *model.URL) {},
+			wantDrifted: true,
+		},
+		{
+			name:        "unmanaged monitors never drift",
+			existing:    func(u *model.URL) { u.Managed = false },
+			edit:        func(u *model.URL) { u.Interval = model.Duration(30 * time.Second) },
+			wantDrifted: false,
+			wantFields:  []string{"interval"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := existing
+			if tt.existing != nil {
+				tt.existing(&before)
+			}
+			url := before
+			url.Managed, url.Drifted = false, false
+			tt.edit(&url)
+
+			if err := markManualEdit(&url, before); err != nil {
+				t.Fatalf("markManualEdit() error = %v", err)
+			}
+			if url.Drifted != tt.wantDrifted {
+				t.Errorf("Drifted = %v, want %v", url.Drifted, tt.wantDrifted)
+			}
+			if url.Managed != before.Managed {
+				t.Errorf("Managed = %v, want %v", url.Managed, before.Managed)
+			}
+			if before.Managed && url.Key != before.Key {
+				t.Errorf("Key = %q, want %q", url.Key, before.Key)
+			}
+			fields, err := changedFields(url, before)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("changedFields() = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+// Test_syncMonitor tests how desired monitors are matched with live ones.
+// Table Driven Test Pattern used
+func Test_syncMonitor(t *testing.T) {
+	live := model.URL{
+		ID:       "id-1",
+		UserID:   "user-1",
+		Key:      "checkout",
+		Address:  "https://pay.example.com/health",
+		Type:     model.MonitorHTTP,
+		Method:   "GET",
+		Interval: model.Duration(time.Minute),
+		Timeout:  model.Duration(10 * time.Second),
+		Managed:  true,
+	}
+	inGroup := func(url model.URL, id, key, address, group string, managed bool) model.URL {
+		url.ID, url.Key, url.Address, url.Group, url.Managed = id, key, address, group, managed
+		return url
+	}
+	others := []model.URL{
+		live,
+		inGroup(live, "id-2", "refunds", "https://pay.example.com/refunds", "payments", true),
+		inGroup(live, "id-3", "search", "https://shop.example.com/search", "shop", true),
+		inGroup(live, "id-4", "legacy", "https://pay.example.com/legacy", "shop", false),
+	}
+
+	tests := []struct {
+		name       string
+		group      string
+		raw        string
+		wantAction string
+		wantErr    bool
+		check      func(t *testing.T, got model.URL)
+	}{
+		{
+			name:       "new monitor",
+			raw:        `{"key": "webhooks", "address": "https://pay.example.com/webhooks"}`,
+			wantAction: model.SyncCreate,
+		},
+		{
+			name:       "runtime fields are ignored",
+			raw:        `{"key": "webhooks", "address": "https://pay.example.com/webhooks", "status": "down", "paused": true, "checked_at": "2020-01-01T00:00:00Z", "state": {"consecutive_failures": 9}, "managed": false}`,
+			wantAction: model.SyncCreate,
+			check: func(t *testing.T, got model.URL) {
+				if got.Status != "" || got.Paused || !got.CheckedAt.IsZero() || got.State.ConsecutiveFailures != 0 || !got.Managed {
+					t.Errorf("got status %q paused %v checked_at %v state %+v managed %v",
+						got.Status, got.Paused, got.CheckedAt, got.State, got.Managed)
+				}
+			},
+		},
+		{
+			name:       "matched by key",
+			raw:        `{"key": "checkout", "address": "https://pay.example.com/health", "interval": "30s"}`,
+			wantAction: model.SyncUpdate,
+		},
+		{
+			name:       "group sync adopts an unmanaged monitor of another group",
+			group:      "payments",
+			raw:        `{"key": "legacy", "address": "https://pay.example.com/legacy"}`,
+			wantAction: model.SyncAdopt,
+			check: func(t *testing.T, got model.URL) {
+				if got.Group != "payments" {
+					t.Errorf("Group = %q, want payments", got.Group)
+				}
+			},
+		},
+		{
+			name:       "group sync updates its own monitor",
+			group:      "payments",
+			raw:        `{"key": "refunds", "address": "https://pay.example.com/refunds", "interval": "30s"}`,
+			wantAction: model.SyncUpdate,
+		},
+		{
+			name:    "group sync can't take a monitor managed by another group",
+			group:   "payments",
+			raw:     `{"key": "search", "address": "https://shop.example.com/search"}`,
+			wantErr: true,
+		},
+		{
+			name:    "group sync can't take a monitor managed without a group",
+			group:   "payments",
+			raw:     `{"key": "checkout", "address": "https://pay.example.com/health"}`,
+			wantErr: true,
+		},
+		{
+			name:    "key required",
+			raw:     `{"address": "https://pay.example.com/health"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &urlService{}
+			byKey := map[string]model.URL{}
+			byAddress := map[string]model.URL{}
+			for _, url := range others {
+				byKey[url.Key], byAddress[url.Address] = url, url
+			}
+
+			got, change, err := s.syncMonitor(context.Background(), "user-1", tt.group, json.RawMessage(tt.raw), byKey, byAddress)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("syncMonitor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if change.Action != tt.wantAction {
+				t.Errorf("Action = %q, want %q", change.Action, tt.wantAction)
+			}
+			if tt.check != nil {
+				tt.check(t, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/samims/hcaas/services/url/internal/assertion"
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// normalizeTransactionSpec validates the steps of a transaction monitor. Every
+// {{name}} a step references must be extracted by an earlier step.
+func normalizeTransactionSpec(u *model.URL) error {
+	if u.Transaction == nil || len(u.Transaction.Steps) == 0 {
+		return appErr.NewInvalid("transaction monitors need at least one step")
+	}
+	if len(u.Transaction.Steps) > model.MaxTransactionSteps {
+		return appErr.NewInvalid("transaction monitors allow at most %d steps", model.MaxTransactionSteps)
+	}
+
+	defined := map[string]bool{}
+	names := map[string]bool{}
+	for i := range u.Transaction.Steps {
+		step := &u.Transaction.Steps[i]
+		step.Name = strings.TrimSpace(step.Name)
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("step %d", i+1)
+		}
+		if names[step.Name] {
+			return appErr.NewInvalid("duplicate step name %q", step.Name)
+		}
+		names[step.Name] = true
+
+		if err := normalizeTransactionStep(step, defined); err != nil {
+			return appErr.NewInvalid("step %q: %v", step.Name, err)
+		}
+		for _, e := range step.Extract {
+			if defined[e.Name] {
+				return appErr.NewInvalid("step %q: variable %q is already extracted by an earlier step", step.Name, e.Name)
+			}
+			defined[e.Name] = true
+		}
+	}
+
+	u.Address = strings.TrimSpace(u.Address)
+	if u.Address == "" {
+		u.Address = u.Transaction.Steps[0].URL
+	}
+	if parsed, err := url.Parse(u.Address); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return appErr.NewInvalid("address %q must be an absolute http(s) URL", u.Address)
+	}
+	// Assertions belong to the individual steps
+	return validateAssertions(u, noAssertions)
+}
+
+// normalizeTransactionStep validates one step given the variables extracted before it
+func normalizeTransactionStep(step *model.TransactionStep, defined map[string]bool) error {
+	step.Method = strings.ToUpper(strings.TrimSpace(step.Method))
+	if step.Method == "" {
+		step.Method = model.DefaultCheckMethod
+	}
+	if !allowedCheckMethods[step.Method] {
+		return fmt.Errorf("unsupported method %q", step.Method)
+	}
+	if step.Body != "" && (step.Method == http.MethodGet || step.Method == http.MethodHead) {
+		return fmt.Errorf("request body is not allowed for %s requests", step.Method)
+	}
+
+	templated := []string{step.URL, step.Body}
+	for name, value := range step.Headers {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("header names must not be empty")
+		}
+		templated = append(templated, value)
+	}
+	placeholders := map[string]string{}
+	for _, s := range templated {
+		for _, ref := range assertion.References(s) {
+			if !defined[ref] {
+				return fmt.Errorf("{{%s}} is not extracted by an earlier step", ref)
+			}
+			placeholders[ref] = "x"
+		}
+	}
+
+	// Check the URL is well-formed with the variables filled in
+	rendered, _ := assertion.Render(step.URL, placeholders)
+	parsed, err := url.Parse(rendered)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("url %q must be an absolute http(s) URL", step.URL)
+	}
+
+	if _, err := assertion.Compile(step.Assertions); err != nil {
+		return err
+	}
+	for _, e := range step.Extract {
+		if _, err := assertion.CompileExtraction(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
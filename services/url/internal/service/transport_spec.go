@@ -0,0 +1,83 @@
+package service
+
+import (
+	"crypto/x509"
+	"net"
+	"net/url"
+	"strings"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// proxySchemes are the proxy protocols net/http can speak
+var proxySchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"socks5": true,
+}
+
+// normalizeTransport validates per-monitor transport settings. Ownership of
+// the client certificate is checked by the caller, which has the store.
+func normalizeTransport(t *model.TransportConfig) error {
+	if t == nil {
+		return nil
+	}
+
+	t.ClientCertID = strings.TrimSpace(t.ClientCertID)
+	if t.CABundle != "" {
+		if t.InsecureSkipVerify {
+			return appErr.NewInvalid("transport ca_bundle and insecure_skip_verify are mutually exclusive")
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM([]byte(t.CABundle)) {
+			return appErr.NewInvalid("transport ca_bundle must contain at least one PEM certificate")
+		}
+	}
+
+	t.Proxy = strings.TrimSpace(t.Proxy)
+	if t.Proxy != "" {
+		parsed, err := url.Parse(t.Proxy)
+		if err != nil || !proxySchemes[parsed.Scheme] || parsed.Host == "" {
+			return appErr.NewInvalid("transport proxy must be an http://, https:// or socks5:// URL")
+		}
+	}
+
+	if t.FollowRedirects != nil && !*t.FollowRedirects {
+		t.MaxRedirects = 0
+	} else {
+		if t.MaxRedirects == 0 {
+			t.MaxRedirects = model.DefaultMaxRedirects
+		}
+		if t.MaxRedirects < 0 || t.MaxRedirects > model.MaxRedirectHops {
+			return appErr.NewInvalid("transport max_redirects must be between 1 and %d", model.MaxRedirectHops)
+		}
+	}
+
+	if t.IPVersion != 0 && t.IPVersion != 4 && t.IPVersion != 6 {
+		return appErr.NewInvalid("transport ip_version must be 4 or 6")
+	}
+
+	t.Resolver = strings.TrimSpace(t.Resolver)
+	if t.Resolver != "" {
+		if err := validateHostPort(t.Resolver); err != nil {
+			return appErr.NewInvalid("transport resolver %q must be host:port", t.Resolver)
+		}
+	}
+
+	overrides := make(map[string]string, len(t.HostOverrides))
+	for host, ip := range t.HostOverrides {
+		host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+		if !validDomainName(host) {
+			return appErr.NewInvalid("transport host_overrides key %q must be a host name", host)
+		}
+		if net.ParseIP(strings.TrimSpace(ip)) == nil {
+			return appErr.NewInvalid("transport host_overrides[%q] must be an IP address", host)
+		}
+		overrides[host] = strings.TrimSpace(ip)
+	}
+	t.HostOverrides = nil
+	if len(overrides) > 0 {
+		t.HostOverrides = overrides
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// MaxReportWindow bounds how far back uptime can be computed in one request
+const MaxReportWindow = 366 * 24 * time.Hour
+
+// GetUptime computes availability of a URL owned by the user in ctx over [from, to)
+func (s *urlService) GetUptime(ctx context.Context, id string, from, to time.Time) (*model.UptimeReport, error) {
+	s.logger.Info("GetUptime called", slog.String("id", id))
+
+	if err := validateWindow(from, to); err != nil {
+		return nil, err
+	}
+
+	url, err := s.findOwned(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.uptimeReport(ctx, url, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// GetUserReport computes availability of every URL the user in ctx owns over
+// [from, to). The status changes of all of them come from one query.
+func (s *urlService) GetUserReport(ctx context.Context, from, to time.Time) (*model.UserReport, error) {
+	s.logger.Info("GetUserReport called")
+
+	if err := validateWindow(from, to); err != nil {
+		return nil, err
+	}
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	urls, err := s.GetAllByUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.store.FindUserStatusChanges(ctx, userID, from, to)
+	if err != nil {
+		s.logger.Error("failed to fetch status changes",
+			slog.String("user_id", userID),
+			slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to compute uptime: %v", err)
+	}
+
+	report := &model.UserReport{From: from, To: to, URLs: []model.UptimeReport{}}
+	for _, url := range urls {
+		report.URLs = append(report.URLs, buildUptimeReport(url, changes[url.ID], from, to))
+	}
+	report.Overall = aggregateUptime(report.URLs)
+
+	s.logger.Info("GetUserReport succeeded", slog.Int("count", len(report.URLs)))
+	return report, nil
+}
+
+func (s *urlService) uptimeReport(ctx context.Context, url model.URL, from, to time.Time) (model.UptimeReport, error) {
+	changes, err := s.store.FindStatusChanges(ctx, url.ID, from, to)
+	if err != nil {
+		s.logger.Error("failed to fetch status changes",
+			slog.String("id", url.ID),
+			slog.String("error", err.Error()))
+		return model.UptimeReport{}, appErr.NewInternal("failed to compute uptime: %v", err)
+	}
+	return buildUptimeReport(url, changes, from, to), nil
+}
+
+func buildUptimeReport(url model.URL, changes []model.StatusChange, from, to time.Time) model.UptimeReport {
+	return model.UptimeReport{
+		URLID:       url.ID,
+		Address:     url.Address,
+		From:        from,
+		To:          to,
+		UptimeStats: computeUptime(changes, from, to, observedUntil(url, time.Now())),
+	}
+}
+
+// observedUntil is when the monitor's last recorded outcome stops counting:
+// when its next check was due, as paused, deleted and stalled monitors record
+// nothing more, and at the latest when it was deleted or now
+func observedUntil(url model.URL, now time.Time) time.Time {
+	due := url.Interval.Std()
+	if due <= 0 {
+		due = model.DefaultCheckInterval.Std()
+	}
+	// A missed heartbeat is only recorded once the grace period is over too
+	if url.Type == model.MonitorHeartbeat {
+		grace := model.DefaultHeartbeatGrace
+		if url.Heartbeat != nil {
+			grace = url.Heartbeat.Grace
+		}
+		due += grace.Std()
+	}
+
+	until := url.CheckedAt.Add(due)
+	if url.DeletedAt != nil && url.DeletedAt.Before(until) {
+		until = *url.DeletedAt
+	}
+	if now.Before(until) {
+		until = now
+	}
+	return until
+}
+
+func validateWindow(from, to time.Time) error {
+	if !from.Before(to) {
+		return appErr.NewInvalid("from must be before to")
+	}
+	if to.Sub(from) > MaxReportWindow {
+		return appErr.NewInvalid("report window must not exceed %s", MaxReportWindow)
+	}
+	return nil
+}
+
+// computeUptime walks the status changes and measures healthy and unhealthy time
+// inside [from, min(to, until)). Each outcome holds until the next change; the
+// last one until the time observedUntil gives.
+func computeUptime(changes []model.StatusChange, from, to, until time.Time) model.UptimeStats {
+	end := to
+	if until.Before(end) {
+		end = until
+	}
+
+	var up, down time.Duration
+	incidents := 0
+	for i, c := range changes {
+		segStart := c.At
+		if segStart.Before(from) {
+			segStart = from
+		}
+		segEnd := end
+		if i+1 < len(changes) && changes[i+1].At.Before(end) {
+			segEnd = changes[i+1].At
+		}
+		if !segStart.Before(segEnd) {
+			continue
+		}
+
+		switch c.Status {
+		case model.StatusHealthy:
+			up += segEnd.Sub(segStart)
+		case model.StatusUnhealthy:
+			down += segEnd.Sub(segStart)
+			incidents++
+		}
+	}
+
+	return uptimeStats(up, down, incidents)
+}
+
+// aggregateUptime combines per-URL stats by summing the underlying durations
+func aggregateUptime(reports []model.UptimeReport) model.UptimeStats {
+	var up, down time.Duration
+	incidents := 0
+	for _, r := range reports {
+		down += r.Downtime.Std()
+		up += r.Monitored.Std() - r.Downtime.Std()
+		incidents += r.Incidents
+	}
+	return uptimeStats(up, down, incidents)
+}
+
+func uptimeStats(up, down time.Duration, incidents int) model.UptimeStats {
+	stats := model.UptimeStats{
+		Monitored: model.Duration(up + down),
+		Downtime:  model.Duration(down),
+		Incidents: incidents,
+	}
+	if up+down > 0 {
+		pct := float64(up) / float64(up+down) * 100
+		stats.UptimePercent = &pct
+	}
+	if incidents > 0 {
+		stats.MTTR = model.Duration(down / time.Duration(incidents))
+		stats.MTBF = model.Duration(up / time.Duration(incidents))
+	}
+	return stats
+}
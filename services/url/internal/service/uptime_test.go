@@ -0,0 +1,115 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// Test_computeUptime tests uptime, downtime, incident and MTTR/MTBF maths.
+// Table Driven Test Pattern used
+func Test_computeUptime(t *testing.T) {
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(10 * time.Hour)
+	at := func(h float64) time.Time { return from.Add(time.Duration(h * float64(time.Hour))) }
+	hours := func(h float64) model.Duration { return model.Duration(time.Duration(h * float64(time.Hour))) }
+
+	tests := []struct {
+		name    string
+		changes []model.StatusChange
+		until   time.Time
+		want    model.UptimeStats
+		wantPct float64
+	}{
+		{
+			name:    "no data",
+			until:   to,
+			want:    model.UptimeStats{},
+			wantPct: -1,
+		},
+		{
+			name:    "healthy before window and throughout",
+			changes: []model.StatusChange{{At: from.Add(-time.Hour), Status: model.StatusHealthy}},
+			until:   to,
+			want:    model.UptimeStats{Monitored: hours(10)},
+			wantPct: 100,
+		},
+		{
+			name: "two outages",
+			changes: []model.StatusChange{
+				{At: at(0), Status: model.StatusHealthy},
+				{At: at(2), Status: model.StatusUnhealthy},
+				{At: at(3), Status: model.StatusHealthy},
+				{At: at(6), Status: model.StatusUnhealthy},
+				{At: at(7), Status: model.StatusHealthy},
+			},
+			until:   to,
+			want:    model.UptimeStats{Monitored: hours(10), Downtime: hours(2), Incidents: 2, MTTR: hours(1), MTBF: hours(4)},
+			wantPct: 80,
+		},
+		{
+			name: "outage running into the window and unknown gap excluded",
+			changes: []model.StatusChange{
+				{At: from.Add(-time.Hour), Status: model.StatusUnhealthy},
+				{At: at(1), Status: model.StatusUnknown},
+				{At: at(2), Status: model.StatusHealthy},
+			},
+			until:   to,
+			want:    model.UptimeStats{Monitored: hours(9), Downtime: hours(1), Incidents: 1, MTTR: hours(1), MTBF: hours(8)},
+			wantPct: 800.0 / 9,
+		},
+		{
+			name: "paused while down stops one interval after the last check",
+			changes: []model.StatusChange{
+				{At: at(0), Status: model.StatusHealthy},
+				{At: at(2), Status: model.StatusUnhealthy},
+			},
+			// paused after a check at 3h, with a 30m interval
+			until:   observedUntil(model.URL{Paused: true, CheckedAt: at(3), Interval: model.Duration(30 * time.Minute)}, to),
+			want:    model.UptimeStats{Monitored: hours(3.5), Downtime: hours(1.5), Incidents: 1, MTTR: hours(1.5), MTBF: hours(2)},
+			wantPct: 200.0 / 3.5,
+		},
+		{
+			name:    "deleted monitor stops at the delete",
+			changes: []model.StatusChange{{At: at(0), Status: model.StatusUnhealthy}},
+			until:   observedUntil(model.URL{CheckedAt: at(5), Interval: model.Duration(time.Hour), DeletedAt: ptrTime(at(5.25))}, to),
+			want:    model.UptimeStats{Monitored: hours(5.25), Downtime: hours(5.25), Incidents: 1, MTTR: hours(5.25)},
+			wantPct: 0,
+		},
+		{
+			name:    "last check older than the window counts nothing",
+			changes: []model.StatusChange{{At: from.Add(-48 * time.Hour), Status: model.StatusUnhealthy}},
+			until:   observedUntil(model.URL{CheckedAt: from.Add(-47 * time.Hour), Interval: model.Duration(time.Minute)}, to),
+			want:    model.UptimeStats{},
+			wantPct: -1,
+		},
+		{
+			name:    "window ending in the future stops at now",
+			changes: []model.StatusChange{{At: at(0), Status: model.StatusHealthy}},
+			until:   at(4),
+			want:    model.UptimeStats{Monitored: hours(4)},
+			wantPct: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeUptime(tt.changes, from, to, tt.until)
+
+			if tt.wantPct < 0 {
+				if got.UptimePercent != nil {
+					t.Errorf("UptimePercent = %v, want nil", *got.UptimePercent)
+				}
+			} else if got.UptimePercent == nil || *got.UptimePercent-tt.wantPct > 1e-9 || tt.wantPct-*got.UptimePercent > 1e-9 {
+				t.Errorf("UptimePercent = %v, want %v", got.UptimePercent, tt.wantPct)
+			}
+
+			got.UptimePercent = nil
+			if got != tt.want {
+				t.Errorf("computeUptime() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time { return &t }
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -38,11 +39,33 @@ func getUserIDFromContext(ctx context.Context) (string, error) {
 }
 
 type URLService interface {
-	GetAll(ctx context.Context) ([]model.URL, error)
+	GetChangedSince(ctx context.Context, since time.Time) ([]model.URL, error)
 	GetByID(ctx context.Context, id string) (*model.URL, error)
+	GetForCheck(ctx context.Context, id string) (*model.URL, error)
 	GetAllByUserID(ctx context.Context) ([]model.URL, error)
-	Add(ctx context.Context, url model.URL) error
+	List(ctx context.Context, q model.URLQuery) (model.Page[model.URL], error)
+	Add(ctx context.Context, url model.URL) (*model.URL, error)
+	Update(ctx context.Context, id string, url model.URL) (*model.URL, error)
+	Import(ctx context.Context, rows []json.RawMessage, dryRun bool) (*model.ImportReport, error)
+	Sync(ctx context.Context, doc model.SyncDocument, dryRun bool) (*model.SyncPlan, error)
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) (*model.URL, error)
+	SetPaused(ctx context.Context, id string, paused bool) (*model.URL, error)
+	ListGroups(ctx context.Context) ([]model.GroupSummary, error)
+	GetGroup(ctx context.Context, name string) (*model.GroupSummary, error)
+	SetGroupPaused(ctx context.Context, name string, paused bool) (*model.GroupSummary, error)
 	UpdateStatus(ctx context.Context, id string, status string) error
+	RecordCheck(ctx context.Context, result *model.CheckResult, status string, state model.CheckState) error
+	RecordHeartbeat(ctx context.Context, result *model.CheckResult, status string, state model.CheckState, loaded model.URL) error
+	MarkRunStarted(ctx context.Context, id string, at time.Time) error
+	GetByHeartbeatToken(ctx context.Context, token string) (*model.URL, error)
+	GetContent(ctx context.Context, id string) (*model.ContentReview, error)
+	AcceptContent(ctx context.Context, id string) (*model.ContentSnapshot, error)
+	InitContentBaseline(ctx context.Context, id string, snapshot model.ContentSnapshot) error
+	SaveContentChanged(ctx context.Context, id string, snapshot model.ContentSnapshot) error
+	GetChecks(ctx context.Context, id string, q model.CheckQuery) (model.Page[model.CheckResult], error)
+	GetUptime(ctx context.Context, id string, from, to time.Time) (*model.UptimeReport, error)
+	GetUserReport(ctx context.Context, from, to time.Time) (*model.UserReport, error)
 }
 
 type urlService struct {
@@ -56,7 +79,6 @@ func NewURLService(store storage.Storage, logger *slog.Logger) URLService {
 }
 
 // GetAllByUserID fetches urls for the user
-// TODO: Bug 	userID, err := getUserIDFromContext(ctx) is being called from checker
 func (s *urlService) GetAllByUserID(ctx context.Context) ([]model.URL, error) {
 	s.logger.Info("GetAll called")
 
@@ -77,38 +99,100 @@ func (s *urlService) GetAllByUserID(ctx context.Context) ([]model.URL, error) {
 	return userURLs, nil
 }
 
-func (s *urlService) GetAll(_ context.Context) ([]model.URL, error) {
-	s.logger.Info("GetAll called")
+// List returns a page of the user's monitors, filtered and sorted as q asks
+func (s *urlService) List(ctx context.Context, q model.URLQuery) (model.Page[model.URL], error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return model.Page[model.URL]{}, err
+	}
+	if !q.CheckedFrom.IsZero() && !q.CheckedTo.IsZero() && !q.CheckedFrom.Before(q.CheckedTo) {
+		return model.Page[model.URL]{}, appErr.NewInvalid("checked_from must be before checked_to")
+	}
 
-	urls, err := s.store.FindAll()
+	page, err := s.store.FindURLs(ctx, userID, q)
 	if err != nil {
-		s.logger.Error("failed to fetch URLs", slog.String("error", err.Error()))
-		return nil, appErr.NewInternal("failed to fetch URLs: %v", err)
+		if appErr.IsInvalid(err) {
+			return page, err
+		}
+		s.logger.Error("failed to list URLs",
+			slog.String("user_id", userID),
+			slog.String("error", err.Error()))
+		return page, appErr.NewInternal("failed to list URLs: %v", err)
 	}
+	return page, nil
+}
 
+// GetChangedSince returns URLs added or reconfigured since the given time.
+// Not user-scoped; the checker's scheduler uses it to stay in sync incrementally.
+func (s *urlService) GetChangedSince(ctx context.Context, since time.Time) ([]model.URL, error) {
+	urls, err := s.store.FindUpdatedSince(ctx, since)
+	if err != nil {
+		s.logger.Error("failed to fetch changed URLs", slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to fetch changed URLs: %v", err)
+	}
 	return urls, nil
-
 }
 
 func (s *urlService) GetByID(ctx context.Context, id string) (*model.URL, error) {
 	s.logger.Info("GetByID called", slog.String("id", id))
 
-	userID, err := getUserIDFromContext(ctx)
+	url, err := s.findOwned(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	s.logger.Info("GetByID succeeded", slog.String("id", id), slog.String("user_id", url.UserID))
+	return &url, nil
+}
+
+// GetForCheck loads a URL with its latest check state, regardless of owner.
+// The checker uses it when it takes over a URL another replica was checking.
+func (s *urlService) GetForCheck(_ context.Context, id string) (*model.URL, error) {
+	url, err := s.store.FindByID(id)
+	if err != nil {
+		if appErr.IsNotFound(err) {
+			return nil, appErr.NewNotFound("url not found")
+		}
+		s.logger.Error("failed to fetch URL", slog.String("id", id), slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to fetch URL: %v", err)
+	}
+	if url.DeletedAt != nil {
+		return nil, appErr.NewNotFound("url not found")
+	}
+	return &url, nil
+}
+
+// findOwned loads a URL and verifies it belongs to the user in ctx.
+// URLs owned by someone else, or deleted, are reported as not found.
+func (s *urlService) findOwned(ctx context.Context, id string) (model.URL, error) {
+	url, err := s.findOwnedIncludingDeleted(ctx, id)
+	if err != nil {
+		return model.URL{}, err
+	}
+	if url.DeletedAt != nil {
+		return model.URL{}, appErr.NewNotFound(fmt.Sprintf("URL with ID %s not found", id))
+	}
+	return url, nil
+}
+
+// findOwnedIncludingDeleted is findOwned for soft-deleted URLs too
+func (s *urlService) findOwnedIncludingDeleted(ctx context.Context, id string) (model.URL, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return model.URL{}, err
+	}
+
 	url, err := s.store.FindByID(id)
 	if err != nil {
 		if errors.Is(err, appErr.ErrNotFound) {
 			s.logger.Warn("URL not found", slog.String("id", id), slog.String("user_id", userID))
-			return nil, appErr.NewNotFound(fmt.Sprintf("URL with ID %s not found", id))
+			return model.URL{}, appErr.NewNotFound(fmt.Sprintf("URL with ID %s not found", id))
 		}
 		s.logger.Error("failed to fetch URL by ID",
 			slog.String("id", id),
 			slog.String("user_id", userID),
 			slog.String("error", err.Error()))
-		return nil, appErr.NewInternal("failed to fetch URL by ID: %v", err)
+		return model.URL{}, appErr.NewInternal("failed to fetch URL by ID: %v", err)
 	}
 
 	// Verify URL belongs to requesting user
@@ -117,62 +201,233 @@ func (s *urlService) GetByID(ctx context.Context, id string) (*model.URL, error)
 			slog.String("id", id),
 			slog.String("requested_by", userID),
 			slog.String("owned_by", url.UserID))
-		return nil, appErr.NewNotFound(fmt.Sprintf("URL with ID %s not found", id))
+		return model.URL{}, appErr.NewNotFound(fmt.Sprintf("URL with ID %s not found", id))
 	}
 
-	s.logger.Info("GetByID succeeded", slog.String("id", id), slog.String("user_id", userID))
-	return &url, nil
+	return url, nil
 }
 
-func (s *urlService) Add(ctx context.Context, url model.URL) error {
+func (s *urlService) Add(ctx context.Context, url model.URL) (*model.URL, error) {
 	s.logger.Info("Add url called", slog.String("url", url.Address))
 
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	url.UserID = userID
+	// Tokens are always issued by the server
+	if url.Heartbeat != nil {
+		url.Heartbeat.Token = ""
+	}
+	// Only sync creates managed monitors
+	url.Managed, url.Drifted = false, false
 
-	// Check if URL address already exists for this user
-	existingURL, err := s.store.FindByAddress(url.Address)
-	if err == nil && existingURL.UserID == userID {
-		s.logger.Warn("URL address already exists for user",
-			slog.String("address", url.Address),
-			slog.String("user_id", userID))
-		return appErr.NewConflict("URL address %s already exists", url.Address)
-	} else if !errors.Is(err, appErr.ErrNotFound) {
-		s.logger.Error("failed to check URL address uniqueness",
+	if err := normalizeCheckSpec(&url); err != nil {
+		s.logger.Warn("invalid check spec",
 			slog.String("address", url.Address),
 			slog.String("error", err.Error()))
-		return appErr.NewInternal("failed to check URL address uniqueness: %v", err)
+		return nil, err
+	}
+	if err := normalizeMetadata(&url); err != nil {
+		return nil, err
+	}
+	if err := s.checkCredentials(ctx, url); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAddressFree(ctx, userID, url.Address, ""); err != nil {
+		return nil, err
 	}
 
 	if url.ID == "" {
 		url.ID = uuid.New().String()
 	}
+	// A heartbeat monitor's first ping is due one period after creation
+	if url.Type == model.MonitorHeartbeat {
+		url.CheckedAt = time.Now()
+	}
 	if err := s.store.Save(&url); err != nil {
 		if errors.Is(err, appErr.ErrConflict) {
-			s.logger.Warn("URL already exists", slog.String("id", url.ID))
-			return appErr.NewConflict("URL with ID %s already exists", url.ID)
+			s.logger.Warn("URL already exists", slog.String("id", url.ID), slog.String("key", url.Key))
+			if url.Key != "" {
+				return nil, appErr.NewConflict("URL with ID %s or key %s already exists", url.ID, url.Key)
+			}
+			return nil, appErr.NewConflict("URL with ID %s already exists", url.ID)
 		}
 		s.logger.Error("failed to add URL",
 			slog.String("id", url.ID),
 			slog.String("error", err.Error()))
-		return appErr.NewInternal("failed to add URL: %v", err)
+		return nil, appErr.NewInternal("failed to add URL: %v", err)
 	}
 
 	s.logger.Info("Add succeeded",
 		slog.String("id", url.ID),
 		slog.String("user_id", userID))
+	return &url, nil
+}
+
+// Update replaces the check spec of one of the user's URLs. The heartbeat
+// token is kept, so the job's ping URL stays valid. Changing a monitor that
+// sync manages flags it as drifted, and its key can't change.
+func (s *urlService) Update(ctx context.Context, id string, url model.URL) (*model.URL, error) {
+	s.logger.Info("Update called", slog.String("id", id))
+
+	existing, err := s.findOwned(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	url.ID, url.UserID = existing.ID, existing.UserID
+	if url.Heartbeat != nil {
+		url.Heartbeat.Token = ""
+		if existing.Heartbeat != nil {
+			url.Heartbeat.Token = existing.Heartbeat.Token
+		}
+	}
+
+	if err := normalizeCheckSpec(&url); err != nil {
+		s.logger.Warn("invalid check spec",
+			slog.String("id", id),
+			slog.String("error", err.Error()))
+		return nil, err
+	}
+	if err := normalizeMetadata(&url); err != nil {
+		return nil, err
+	}
+	if err := s.checkCredentials(ctx, url); err != nil {
+		return nil, err
+	}
+	if url.Address != existing.Address {
+		if err := s.checkAddressFree(ctx, existing.UserID, url.Address, id); err != nil {
+			return nil, err
+		}
+	}
+	if err := markManualEdit(&url, existing); err != nil {
+		return nil, appErr.NewInternal("failed to compare monitors: %v", err)
+	}
+
+	if err := s.store.Update(ctx, &url); err != nil {
+		if errors.Is(err, appErr.ErrNotFound) {
+			return nil, appErr.NewNotFound("URL with ID %s not found", id)
+		}
+		if errors.Is(err, appErr.ErrConflict) {
+			return nil, appErr.NewConflict("URL with key %s already exists", url.Key)
+		}
+		s.logger.Error("failed to update URL", slog.String("id", id), slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to update URL: %v", err)
+	}
+
+	s.logger.Info("Update succeeded", slog.String("id", id), slog.String("user_id", existing.UserID))
+	return s.reloadOwned(ctx, id)
+}
+
+// Delete soft-deletes one of the user's URLs; checks stop and Restore brings it back
+func (s *urlService) Delete(ctx context.Context, id string) error {
+	if _, err := s.findOwned(ctx, id); err != nil {
+		return err
+	}
+	if err := s.store.SoftDelete(ctx, id); err != nil {
+		if errors.Is(err, appErr.ErrNotFound) {
+			return appErr.NewNotFound("URL with ID %s not found", id)
+		}
+		s.logger.Error("failed to delete URL", slog.String("id", id), slog.String("error", err.Error()))
+		return appErr.NewInternal("failed to delete URL: %v", err)
+	}
+	s.logger.Info("Delete succeeded", slog.String("id", id))
 	return nil
 }
 
-// UpdateStatus updates the status of a URL by its ID.
-// This is the new, non-user-scoped method for the background checker.
+// Restore undeletes one of the user's URLs, unless its address has been
+// added again since
+func (s *urlService) Restore(ctx context.Context, id string) (*model.URL, error) {
+	url, err := s.findOwnedIncludingDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if url.DeletedAt == nil {
+		return nil, appErr.NewConflict("URL with ID %s is not deleted", id)
+	}
+	if err := s.checkAddressFree(ctx, url.UserID, url.Address, id); err != nil {
+		return nil, err
+	}
+	if err := s.store.Restore(ctx, id); err != nil {
+		if errors.Is(err, appErr.ErrNotFound) {
+			return nil, appErr.NewConflict("URL with ID %s is not deleted", id)
+		}
+		s.logger.Error("failed to restore URL", slog.String("id", id), slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to restore URL: %v", err)
+	}
+	s.logger.Info("Restore succeeded", slog.String("id", id))
+	return s.reloadOwned(ctx, id)
+}
+
+// SetPaused pauses or resumes checks of one of the user's URLs
+func (s *urlService) SetPaused(ctx context.Context, id string, paused bool) (*model.URL, error) {
+	if _, err := s.findOwned(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := s.store.SetPaused(ctx, id, paused); err != nil {
+		if errors.Is(err, appErr.ErrNotFound) {
+			return nil, appErr.NewNotFound("URL with ID %s not found", id)
+		}
+		s.logger.Error("failed to set paused", slog.String("id", id), slog.String("error", err.Error()))
+		return nil, appErr.NewInternal("failed to set paused: %v", err)
+	}
+	s.logger.Info("SetPaused succeeded", slog.String("id", id), slog.Bool("paused", paused))
+	return s.reloadOwned(ctx, id)
+}
+
+// reloadOwned returns the stored copy of a URL just written, with the columns
+// the write left alone
+func (s *urlService) reloadOwned(ctx context.Context, id string) (*model.URL, error) {
+	url, err := s.findOwned(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &url, nil
+}
+
+// checkAddressFree reports a conflict when another live URL of the user, other
+// than the one with exceptID, already has the address
+func (s *urlService) checkAddressFree(ctx context.Context, userID, address, exceptID string) error {
+	inUse, err := s.store.AddressInUse(ctx, userID, address, exceptID)
+	if err != nil {
+		s.logger.Error("failed to check URL address uniqueness",
+			slog.String("address", address),
+			slog.String("error", err.Error()))
+		return appErr.NewInternal("failed to check URL address uniqueness: %v", err)
+	}
+	if inUse {
+		s.logger.Warn("URL address already exists for user",
+			slog.String("address", address),
+			slog.String("user_id", userID))
+		return appErr.NewConflict("URL address %s already exists", address)
+	}
+	return nil
+}
+
+// manualStatuses are the statuses a user may set by hand
+var manualStatuses = map[string]bool{
+	model.StatusUnknown:   true,
+	model.StatusUP:        true,
+	model.StatusDown:      true,
+	model.StatusHealthy:   true,
+	model.StatusUnhealthy: true,
+}
+
+// UpdateStatus overrides the status of one of the user's URLs until its next check
 func (s *urlService) UpdateStatus(ctx context.Context, id string, status string) error {
-	s.logger.Info("UpdateStatus called by bg task", slog.String("id", id), slog.String("status", status))
+	s.logger.Info("UpdateStatus called", slog.String("id", id), slog.String("status", status))
 
+	if !manualStatuses[status] {
+		return appErr.NewInvalid("unsupported status %q", status)
+	}
+	if _, err := s.findOwned(ctx, id); err != nil {
+		return err
+	}
 	if err := s.store.UpdateStatus(id, status, time.Now()); err != nil {
+		if errors.Is(err, appErr.ErrNotFound) {
+			return appErr.NewNotFound("URL with ID %s not found", id)
+		}
 		s.logger.Error("failed to update status", slog.String("id", id), slog.String("error", err.Error()))
 		return appErr.NewInternal("failed to update URL status: %v", err)
 	}
@@ -180,3 +435,34 @@ func (s *urlService) UpdateStatus(ctx context.Context, id string, status string)
 	s.logger.Info("UpdateStatus succeeded", slog.String("id", id), slog.String("status", status))
 	return nil
 }
+
+// checkCredentials verifies the credentials a URL references belong to the
+// user in ctx and are of a kind that fits where they are used
+func (s *urlService) checkCredentials(ctx context.Context, url model.URL) error {
+	if url.CredentialID != "" {
+		c, err := findOwnedCredential(ctx, s.store, url.CredentialID)
+		if err != nil {
+			if appErr.IsNotFound(err) {
+				return appErr.NewInvalid("credential %s not found", url.CredentialID)
+			}
+			return err
+		}
+		if c.Type == model.CredentialClientCert {
+			return appErr.NewInvalid("credential %s is a client certificate; set it as transport.client_cert_id", url.CredentialID)
+		}
+	}
+	if url.Transport != nil && url.Transport.ClientCertID != "" {
+		id := url.Transport.ClientCertID
+		c, err := findOwnedCredential(ctx, s.store, id)
+		if err != nil {
+			if appErr.IsNotFound(err) {
+				return appErr.NewInvalid("credential %s not found", id)
+			}
+			return err
+		}
+		if c.Type != model.CredentialClientCert {
+			return appErr.NewInvalid("transport.client_cert_id %s must be a client_cert credential", id)
+		}
+	}
+	return nil
+}
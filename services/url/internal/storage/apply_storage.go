@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// ApplyURLs soft-deletes the URLs with the deletes IDs, updates the check specs
+// of updates and inserts creates, in that order and in one transaction:
+// either all of them are written or none is. It fails with ErrNotFound when
+// one of the URLs to delete or update is gone, and ErrConflict when a write
+// breaks a unique constraint.
+func (ps *postgresStorage) ApplyURLs(ctx context.Context, creates, updates []model.URL, deletes []string) error {
+	tx, err := ps.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, id := range deletes {
+		cmdTags, err := tx.Exec(ctx, softDeleteQuery, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete URL %s: %w", id, err)
+		}
+		if cmdTags.RowsAffected() == 0 {
+			return appErr.ErrNotFound
+		}
+	}
+
+	for i := range updates {
+		url := &updates[i]
+		if err := tx.QueryRow(ctx, updateURLQuery, updateURLArgs(url)...).Scan(&url.UpdatedAt); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return appErr.ErrNotFound
+			}
+			if isUniqueViolation(err) {
+				return appErr.ErrConflict
+			}
+			return fmt.Errorf("failed to update URL %s: %w", url.ID, err)
+		}
+	}
+
+	for i := range creates {
+		url := &creates[i]
+		err := tx.QueryRow(ctx, insertURLQuery, insertURLArgs(url)...).Scan(&url.ID, &url.CreatedAt, &url.UpdatedAt)
+		if err != nil {
+			return saveError(err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit changes: %w", err)
+	}
+	return nil
+}
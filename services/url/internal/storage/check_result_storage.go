@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+	"github.com/samims/hcaas/services/url/internal/pagination"
+)
+
+// checkResultColumns is the column list every check_results SELECT uses; keep it in sync with scanCheckResult.
+const checkResultColumns = `id, url_id, checked_at, status, status_code, latency_ms,
+		error, error_class, failed_assertions, cert, steps, failed_step, timings, content_hash, attempts`
+
+func scanCheckResult(row pgx.Row) (model.CheckResult, error) {
+	var (
+		r         model.CheckResult
+		latencyMS int64
+	)
+	err := row.Scan(
+		&r.ID, &r.URLID, &r.CheckedAt, &r.Status, &r.StatusCode, &latencyMS,
+		&r.Error, &r.ErrorClass, &r.FailedAssertions, &r.Cert, &r.Steps, &r.FailedStep, &r.Timings, &r.ContentHash, &r.Attempts,
+	)
+	if err != nil {
+		return model.CheckResult{}, err
+	}
+	r.Latency = model.Duration(time.Duration(latencyMS) * time.Millisecond)
+	return r, nil
+}
+
+// MarkRunStarted remembers that a heartbeat monitor's job signalled its start.
+// It sets the one state field in place, so it can't undo a concurrent write.
+func (ps *postgresStorage) MarkRunStarted(ctx context.Context, id string, at time.Time) error {
+	const query = `UPDATE urls SET check_state = jsonb_set(check_state, '{run_started_at}', $2) WHERE id = $1`
+
+	cmdTags, err := ps.db.Exec(ctx, query, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to mark run started: %w", err)
+	}
+	if cmdTags.RowsAffected() == 0 {
+		return appErr.ErrNotFound
+	}
+	return nil
+}
+
+// RecordCheck appends the raw result to the check history and stores the URL's
+// confirmed status and check state in the same transaction.
+func (ps *postgresStorage) RecordCheck(ctx context.Context, r *model.CheckResult, status string, state model.CheckState) error {
+	const updateQuery = `
+		UPDATE urls
+		SET status = $1, checked_at = $2,
+			status_changed_at = CASE WHEN status IS DISTINCT FROM $1 THEN $2 ELSE status_changed_at END,
+			check_state = $4,
+			cert = COALESCE($5, cert),
+			latency_ms = $6
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+	return ps.recordCheck(ctx, r, updateQuery, status, r.CheckedAt, r.URLID, state, r.Cert, r.Latency.Std().Milliseconds())
+}
+
+// RecordHeartbeat records a ping or missed heartbeat like RecordCheck, but only
+// if no check was recorded and no run started since loaded was read; otherwise
+// it returns ErrConflict and the caller reloads. Pings also move last_ping_at,
+// missed heartbeats leave it alone.
+func (ps *postgresStorage) RecordHeartbeat(ctx context.Context, r *model.CheckResult, status string, state model.CheckState, loaded model.URL) error {
+	const updateQuery = `
+		UPDATE urls
+		SET status = $1, checked_at = $2,
+			status_changed_at = CASE WHEN status IS DISTINCT FROM $1 THEN $2 ELSE status_changed_at END,
+			check_state = $4,
+			latency_ms = $5,
+			last_ping_at = CASE WHEN $6 THEN $2 ELSE last_ping_at END
+		WHERE id = $3 AND deleted_at IS NULL
+			AND checked_at = $7 AND check_state -> 'run_started_at' IS NOT DISTINCT FROM $8::jsonb
+	`
+	ping := r.ErrorClass != model.ErrorClassMissedHeartbeat
+	err := ps.recordCheck(ctx, r, updateQuery,
+		status, r.CheckedAt, r.URLID, state, r.Latency.Std().Milliseconds(), ping, loaded.CheckedAt, loaded.State.RunStartedAt)
+	if !errors.Is(err, appErr.ErrNotFound) {
+		return err
+	}
+
+	var exists bool
+	if err := ps.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM urls WHERE id = $1 AND deleted_at IS NULL)`, r.URLID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check url: %w", err)
+	}
+	if exists {
+		return appErr.ErrConflict
+	}
+	return appErr.ErrNotFound
+}
+
+// recordCheck runs the urls update, returning ErrNotFound when it matches no
+// row, and appends the result to the check history in one transaction
+func (ps *postgresStorage) recordCheck(ctx context.Context, r *model.CheckResult, updateQuery string, args ...any) error {
+	const insertQuery = `
+		INSERT INTO check_results(url_id, checked_at, status, status_code, latency_ms,
+			error, error_class, failed_assertions, cert, steps, failed_step, timings, content_hash, attempts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id
+	`
+
+	tx, err := ps.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cmdTags, err := tx.Exec(ctx, updateQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+	if cmdTags.RowsAffected() == 0 {
+		return appErr.ErrNotFound
+	}
+
+	err = tx.QueryRow(ctx, insertQuery,
+		r.URLID, r.CheckedAt, r.Status, r.StatusCode, r.Latency.Std().Milliseconds(),
+		r.Error, r.ErrorClass, r.FailedAssertions, r.Cert, r.Steps, r.FailedStep, r.Timings, r.ContentHash, r.Attempts,
+	).Scan(&r.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert check result: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit check result: %w", err)
+	}
+	return nil
+}
+
+// FindCheckResults returns check history newest first, paginated by (checked_at, id)
+func (ps *postgresStorage) FindCheckResults(ctx context.Context, urlID string, q model.CheckQuery) (model.Page[model.CheckResult], error) {
+	const query = `
+		SELECT ` + checkResultColumns + `
+		FROM check_results
+		WHERE url_id = $1
+		  AND ($2::timestamptz IS NULL OR checked_at >= $2)
+		  AND ($3::timestamptz IS NULL OR checked_at < $3)
+		  AND ($4::timestamptz IS NULL OR (checked_at, id) < ($4, $5))
+		ORDER BY checked_at DESC, id DESC
+		LIMIT $6
+	`
+
+	var page model.Page[model.CheckResult]
+
+	cursor, err := pagination.Decode(q.Cursor)
+	if err != nil {
+		return page, appErr.NewInvalid("%v", err)
+	}
+	var (
+		afterTime *time.Time
+		afterID   int64
+	)
+	if cursor != nil {
+		t, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return page, appErr.NewInvalid("malformed cursor")
+		}
+		if afterID, err = strconv.ParseInt(cursor.ID, 10, 64); err != nil {
+			return page, appErr.NewInvalid("malformed cursor")
+		}
+		afterTime = &t
+	}
+
+	limit := pagination.ClampLimit(q.Limit)
+	rows, err := ps.db.Query(ctx, query, urlID, nullTime(q.From), nullTime(q.To), afterTime, afterID, limit+1)
+	if err != nil {
+		return page, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	page.Items = []model.CheckResult{}
+	for rows.Next() {
+		r, err := scanCheckResult(rows)
+		if err != nil {
+			return page, fmt.Errorf("scan failed: %w", err)
+		}
+		page.Items = append(page.Items, r)
+	}
+	if err := rows.Err(); err != nil {
+		return page, fmt.Errorf("row iteration failed: %w", err)
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = pagination.Encode(pagination.Cursor{
+			Value: last.CheckedAt.Format(time.RFC3339Nano),
+			ID:    strconv.FormatInt(last.ID, 10),
+		})
+	}
+	return page, nil
+}
+
+// nullTime maps the zero time to SQL NULL
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
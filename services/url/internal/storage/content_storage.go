@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// InitContentBaseline stores the first content seen as the baseline. It leaves
+// a baseline that is already set untouched.
+func (ps *postgresStorage) InitContentBaseline(ctx context.Context, id string, snapshot model.ContentSnapshot) error {
+	const query = `UPDATE urls SET content_baseline = $2 WHERE id = $1 AND content_baseline IS NULL`
+
+	if _, err := ps.db.Exec(ctx, query, id, snapshot); err != nil {
+		return fmt.Errorf("failed to init content baseline: %w", err)
+	}
+	return nil
+}
+
+// SaveContentChanged stores content that differs from the baseline
+func (ps *postgresStorage) SaveContentChanged(ctx context.Context, id string, snapshot model.ContentSnapshot) error {
+	const query = `UPDATE urls SET content_changed = $2 WHERE id = $1`
+
+	cmdTags, err := ps.db.Exec(ctx, query, id, snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to save changed content: %w", err)
+	}
+	if cmdTags.RowsAffected() == 0 {
+		return appErr.ErrNotFound
+	}
+	return nil
+}
+
+// AcceptContent makes the changed content the new baseline and bumps
+// updated_at so checkers pick it up on their next sync. It returns ErrNotFound
+// when there is no change to accept.
+func (ps *postgresStorage) AcceptContent(ctx context.Context, id string) (model.ContentSnapshot, error) {
+	const query = `
+		UPDATE urls
+		SET content_baseline = content_changed, content_changed = NULL, updated_at = NOW()
+		WHERE id = $1 AND content_changed IS NOT NULL
+		RETURNING content_baseline
+	`
+
+	var baseline model.ContentSnapshot
+	if err := ps.db.QueryRow(ctx, query, id).Scan(&baseline); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.ContentSnapshot{}, appErr.ErrNotFound
+		}
+		return model.ContentSnapshot{}, fmt.Errorf("failed to accept content: %w", err)
+	}
+	return baseline, nil
+}
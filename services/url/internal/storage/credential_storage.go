@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// credentialColumns is the column list every credentials SELECT uses; keep it in sync with scanCredential.
+const credentialColumns = `id, user_id, name, type, username, header, token_url, client_id, scopes, certificate,
+		secret_key, secret, created_at, updated_at`
+
+func scanCredential(row pgx.Row) (model.Credential, error) {
+	var c model.Credential
+	err := row.Scan(
+		&c.ID, &c.UserID, &c.Name, &c.Type, &c.Username, &c.Header, &c.TokenURL, &c.ClientID, &c.Scopes, &c.Certificate,
+		&c.Sealed.WrappedKey, &c.Sealed.Ciphertext, &c.CreatedAt, &c.UpdatedAt,
+	)
+	return c, err
+}
+
+// SaveCredential inserts a credential with its already sealed secret
+func (ps *postgresStorage) SaveCredential(ctx context.Context, c *model.Credential) error {
+	const query = `
+		INSERT INTO credentials(id, user_id, name, type, username, header, token_url, client_id, scopes, certificate,
+			secret_key, secret)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING created_at, updated_at
+	`
+
+	err := ps.db.QueryRow(ctx, query,
+		c.ID, c.UserID, c.Name, c.Type, c.Username, c.Header, c.TokenURL, c.ClientID, c.Scopes, c.Certificate,
+		c.Sealed.WrappedKey, c.Sealed.Ciphertext,
+	).Scan(&c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+			return appErr.ErrConflict
+		}
+		return fmt.Errorf("failed to save credential: %w", err)
+	}
+	return nil
+}
+
+func (ps *postgresStorage) FindCredentialByID(ctx context.Context, id string) (model.Credential, error) {
+	query := `SELECT ` + credentialColumns + ` FROM credentials WHERE id = $1`
+
+	c, err := scanCredential(ps.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Credential{}, appErr.ErrNotFound
+		}
+		return model.Credential{}, fmt.Errorf("find credential failed: %w", err)
+	}
+	return c, nil
+}
+
+func (ps *postgresStorage) FindCredentialsByUserID(ctx context.Context, userID string) ([]model.Credential, error) {
+	query := `SELECT ` + credentialColumns + ` FROM credentials WHERE user_id = $1 ORDER BY name`
+
+	rows, err := ps.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credentials: %w", err)
+	}
+	defer rows.Close()
+
+	var credentials []model.Credential
+	for rows.Next() {
+		c, err := scanCredential(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan credential: %w", err)
+		}
+		credentials = append(credentials, c)
+	}
+	return credentials, rows.Err()
+}
+
+// DeleteCredential removes a credential. It returns ErrConflict while monitors still use it,
+// through credential_id or the client_cert_id derived from their transport settings.
+func (ps *postgresStorage) DeleteCredential(ctx context.Context, id string) error {
+	cmdTags, err := ps.db.Exec(ctx, `DELETE FROM credentials WHERE id = $1`, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+			return appErr.ErrConflict
+		}
+		return fmt.Errorf("failed to delete credential: %w", err)
+	}
+	if cmdTags.RowsAffected() == 0 {
+		return appErr.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// groupCounts tallies a group's live monitors by status; the status counts
+// leave out paused monitors
+const groupCounts = `group_name,
+		COUNT(*),
+		COUNT(*) FILTER (WHERE NOT paused AND status IN ('healthy', 'up')),
+		COUNT(*) FILTER (WHERE NOT paused AND status IN ('unhealthy', 'down')),
+		COUNT(*) FILTER (WHERE NOT paused AND status = 'flapping'),
+		COUNT(*) FILTER (WHERE NOT paused AND status NOT IN ('healthy', 'up', 'unhealthy', 'down', 'flapping')),
+		COUNT(*) FILTER (WHERE paused)`
+
+// FindGroups returns the counts of each of the user's groups, by name. The
+// aggregate status is left to the caller.
+func (ps *postgresStorage) FindGroups(ctx context.Context, userID string) ([]model.GroupSummary, error) {
+	const query = `
+		SELECT ` + groupCounts + `
+		FROM urls
+		WHERE user_id = $1 AND deleted_at IS NULL AND group_name <> ''
+		GROUP BY group_name
+		ORDER BY group_name
+	`
+
+	rows, err := ps.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []model.GroupSummary{}
+	for rows.Next() {
+		var g model.GroupSummary
+		if err := rows.Scan(&g.Name, &g.Total, &g.Healthy, &g.Unhealthy, &g.Flapping, &g.Unknown, &g.Paused); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration failed: %w", err)
+	}
+	return groups, nil
+}
+
+// FindGroup returns the counts of one of the user's groups. A group exists
+// while it has a live monitor.
+func (ps *postgresStorage) FindGroup(ctx context.Context, userID, name string) (model.GroupSummary, error) {
+	const query = `
+		SELECT ` + groupCounts + `
+		FROM urls
+		WHERE user_id = $1 AND deleted_at IS NULL AND group_name = $2
+		GROUP BY group_name
+	`
+
+	var g model.GroupSummary
+	err := ps.db.QueryRow(ctx, query, userID, name).
+		Scan(&g.Name, &g.Total, &g.Healthy, &g.Unhealthy, &g.Flapping, &g.Unknown, &g.Paused)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return g, appErr.ErrNotFound
+		}
+		return g, fmt.Errorf("find group failed: %w", err)
+	}
+	return g, nil
+}
+
+// SetGroupPaused pauses or resumes every live monitor in one of the user's
+// groups, as SetPaused does for one
+func (ps *postgresStorage) SetGroupPaused(ctx context.Context, userID, name string, paused bool) error {
+	const query = `
+		UPDATE urls
+		SET paused = $3,
+			checked_at = CASE WHEN NOT $3 AND paused AND type = 'heartbeat' THEN NOW() ELSE checked_at END,
+			last_ping_at = CASE WHEN NOT $3 AND paused AND type = 'heartbeat' THEN NOW() ELSE last_ping_at END,
+			updated_at = NOW()
+		WHERE user_id = $1 AND group_name = $2 AND deleted_at IS NULL AND paused <> $3
+	`
+
+	if _, err := ps.db.Exec(ctx, query, userID, name, paused); err != nil {
+		return fmt.Errorf("failed to set group paused: %w", err)
+	}
+	return nil
+}
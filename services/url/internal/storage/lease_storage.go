@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LeaseStorage coordinates checker replicas. URLs are split into a fixed number of
+// shards and each shard is leased to one live instance at a time. All expiry
+// maths uses the database clock so replicas don't need synchronised clocks.
+type LeaseStorage interface {
+	// Heartbeat records the instance as alive and returns the number of live instances.
+	Heartbeat(ctx context.Context, instanceID string, ttl time.Duration) (int, error)
+	// EnsureShards creates lease rows for shards [0, shards).
+	EnsureShards(ctx context.Context, shards int) error
+	// RenewLeases extends every lease the instance holds and returns their shards.
+	RenewLeases(ctx context.Context, instanceID string, ttl time.Duration) ([]int, error)
+	// AcquireLeases claims up to n free or expired shards below shards.
+	AcquireLeases(ctx context.Context, instanceID string, ttl time.Duration, shards, n int) ([]int, error)
+	// ReleaseLeases gives up the listed shards so other instances can take them.
+	ReleaseLeases(ctx context.Context, instanceID string, shards []int) error
+	// Leave releases every lease and removes the instance.
+	Leave(ctx context.Context, instanceID string) error
+}
+
+type postgresLeaseStorage struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresLeaseStorage(pool *pgxpool.Pool) LeaseStorage {
+	return &postgresLeaseStorage{pool}
+}
+
+func (ls *postgresLeaseStorage) Heartbeat(ctx context.Context, instanceID string, ttl time.Duration) (int, error) {
+	const upsert = `
+		INSERT INTO checker_instances(id, heartbeat_at)
+		VALUES ($1, now())
+		ON CONFLICT (id) DO UPDATE SET heartbeat_at = now()
+	`
+	const live = `
+		SELECT count(*)
+		FROM checker_instances
+		WHERE heartbeat_at > now() - $1 * interval '1 millisecond'
+	`
+
+	if _, err := ls.db.Exec(ctx, upsert, instanceID); err != nil {
+		return 0, fmt.Errorf("failed to record heartbeat: %w", err)
+	}
+
+	var count int
+	if err := ls.db.QueryRow(ctx, live, ttl.Milliseconds()).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count live instances: %w", err)
+	}
+	return count, nil
+}
+
+func (ls *postgresLeaseStorage) EnsureShards(ctx context.Context, shards int) error {
+	const query = `
+		INSERT INTO checker_leases(shard, owner, expires_at)
+		SELECT s, NULL, 'epoch'::timestamptz FROM generate_series(0, $1 - 1) AS s
+		ON CONFLICT (shard) DO NOTHING
+	`
+	if _, err := ls.db.Exec(ctx, query, shards); err != nil {
+		return fmt.Errorf("failed to create shard leases: %w", err)
+	}
+	return nil
+}
+
+func (ls *postgresLeaseStorage) RenewLeases(ctx context.Context, instanceID string, ttl time.Duration) ([]int, error) {
+	const query = `
+		UPDATE checker_leases
+		SET expires_at = now() + $2 * interval '1 millisecond'
+		WHERE owner = $1 AND expires_at > now()
+		RETURNING shard
+	`
+	rows, err := ls.db.Query(ctx, query, instanceID, ttl.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to renew leases: %w", err)
+	}
+	return collectShards(rows)
+}
+
+func (ls *postgresLeaseStorage) AcquireLeases(ctx context.Context, instanceID string, ttl time.Duration, shards, n int) ([]int, error) {
+	const query = `
+		UPDATE checker_leases
+		SET owner = $1, expires_at = now() + $2 * interval '1 millisecond'
+		WHERE shard IN (
+			SELECT shard
+			FROM checker_leases
+			WHERE shard < $3 AND (owner IS NULL OR expires_at <= now())
+			ORDER BY shard
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING shard
+	`
+	if n <= 0 {
+		return nil, nil
+	}
+	rows, err := ls.db.Query(ctx, query, instanceID, ttl.Milliseconds(), shards, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire leases: %w", err)
+	}
+	return collectShards(rows)
+}
+
+func (ls *postgresLeaseStorage) ReleaseLeases(ctx context.Context, instanceID string, shards []int) error {
+	const query = `
+		UPDATE checker_leases
+		SET owner = NULL, expires_at = 'epoch'::timestamptz
+		WHERE owner = $1 AND shard = ANY($2)
+	`
+	if len(shards) == 0 {
+		return nil
+	}
+	if _, err := ls.db.Exec(ctx, query, instanceID, shards); err != nil {
+		return fmt.Errorf("failed to release leases: %w", err)
+	}
+	return nil
+}
+
+func (ls *postgresLeaseStorage) Leave(ctx context.Context, instanceID string) error {
+	const release = `
+		UPDATE checker_leases
+		SET owner = NULL, expires_at = 'epoch'::timestamptz
+		WHERE owner = $1
+	`
+	const remove = `DELETE FROM checker_instances WHERE id = $1`
+
+	if _, err := ls.db.Exec(ctx, release, instanceID); err != nil {
+		return fmt.Errorf("failed to release leases: %w", err)
+	}
+	if _, err := ls.db.Exec(ctx, remove, instanceID); err != nil {
+		return fmt.Errorf("failed to remove instance: %w", err)
+	}
+	return nil
+}
+
+func collectShards(rows pgx.Rows) ([]int, error) {
+	defer rows.Close()
+
+	var shards []int
+	for rows.Next() {
+		var shard int
+		if err := rows.Scan(&shard); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		shards = append(shards, shard)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration failed: %w", err)
+	}
+	return shards, nil
+}
@@ -17,11 +17,34 @@ import (
 type Storage interface {
 	Ping(ctx context.Context) error
 	Save(url *model.URL) error
-	FindAll() ([]model.URL, error)
 	FindAllByUserID(ctx context.Context, userID string) ([]model.URL, error)
+	FindURLs(ctx context.Context, userID string, q model.URLQuery) (model.Page[model.URL], error)
 	FindByID(id string) (model.URL, error)
-	FindByAddress(address string) (model.URL, error)
+	AddressInUse(ctx context.Context, userID, address, exceptID string) (bool, error)
+	FindByHeartbeatToken(ctx context.Context, token string) (model.URL, error)
+	FindUpdatedSince(ctx context.Context, since time.Time) ([]model.URL, error)
+	Update(ctx context.Context, url *model.URL) error
+	ApplyURLs(ctx context.Context, creates, updates []model.URL, deletes []string) error
+	SetPaused(ctx context.Context, id string, paused bool) error
+	FindGroups(ctx context.Context, userID string) ([]model.GroupSummary, error)
+	FindGroup(ctx context.Context, userID, name string) (model.GroupSummary, error)
+	SetGroupPaused(ctx context.Context, userID, name string, paused bool) error
+	SoftDelete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 	UpdateStatus(id, status string, checkedAt time.Time) error
+	RecordCheck(ctx context.Context, result *model.CheckResult, status string, state model.CheckState) error
+	RecordHeartbeat(ctx context.Context, result *model.CheckResult, status string, state model.CheckState, loaded model.URL) error
+	MarkRunStarted(ctx context.Context, id string, at time.Time) error
+	InitContentBaseline(ctx context.Context, id string, snapshot model.ContentSnapshot) error
+	SaveContentChanged(ctx context.Context, id string, snapshot model.ContentSnapshot) error
+	AcceptContent(ctx context.Context, id string) (model.ContentSnapshot, error)
+	SaveCredential(ctx context.Context, c *model.Credential) error
+	FindCredentialByID(ctx context.Context, id string) (model.Credential, error)
+	FindCredentialsByUserID(ctx context.Context, userID string) ([]model.Credential, error)
+	DeleteCredential(ctx context.Context, id string) error
+	FindCheckResults(ctx context.Context, urlID string, q model.CheckQuery) (model.Page[model.CheckResult], error)
+	FindStatusChanges(ctx context.Context, urlID string, from, to time.Time) ([]model.StatusChange, error)
+	FindUserStatusChanges(ctx context.Context, userID string, from, to time.Time) (map[string][]model.StatusChange, error)
 }
 
 type postgresStorage struct {
@@ -32,6 +55,43 @@ func NewPostgresStorage(pool *pgxpool.Pool) Storage {
 	return &postgresStorage{pool}
 }
 
+// urlColumns is the column list every urls SELECT uses; keep it in sync with scanURL.
+const urlColumns = `id, user_id, address, status, checked_at, status_changed_at, created_at, updated_at,
+		method, headers, body, timeout_ms, interval_ms, assertions,
+		failure_threshold, recovery_threshold, flap_threshold, flap_window_ms, check_state,
+		type, tcp, dns, tls, cert, grpc, heartbeat, transaction,
+		content, content_baseline, content_changed, COALESCE(credential_id, ''), transport, retry,
+		paused, deleted_at, name, tags, latency_ms, labels, group_name,
+		external_key, managed, drifted, last_ping_at`
+
+// scanURL scans a row selected with urlColumns. pgx.Rows satisfies pgx.Row.
+func scanURL(row pgx.Row) (model.URL, error) {
+	var (
+		url          model.URL
+		timeoutMS    int64
+		intervalMS   int64
+		flapWindowMS int64
+		latencyMS    int64
+	)
+	err := row.Scan(
+		&url.ID, &url.UserID, &url.Address, &url.Status, &url.CheckedAt, &url.StatusChangedAt, &url.CreatedAt, &url.UpdatedAt,
+		&url.Method, &url.Headers, &url.Body, &timeoutMS, &intervalMS, &url.Assertions,
+		&url.FailureThreshold, &url.RecoveryThreshold, &url.FlapThreshold, &flapWindowMS, &url.State,
+		&url.Type, &url.TCP, &url.DNS, &url.TLS, &url.Cert, &url.GRPC, &url.Heartbeat, &url.Transaction,
+		&url.Content, &url.ContentBaseline, &url.ContentChanged, &url.CredentialID, &url.Transport, &url.Retry,
+		&url.Paused, &url.DeletedAt, &url.Name, &url.Tags, &latencyMS, &url.Labels, &url.Group,
+		&url.Key, &url.Managed, &url.Drifted, &url.LastPingAt,
+	)
+	if err != nil {
+		return model.URL{}, err
+	}
+	url.Timeout = model.Duration(time.Duration(timeoutMS) * time.Millisecond)
+	url.Interval = model.Duration(time.Duration(intervalMS) * time.Millisecond)
+	url.FlapWindow = model.Duration(time.Duration(flapWindowMS) * time.Millisecond)
+	url.Latency = model.Duration(time.Duration(latencyMS) * time.Millisecond)
+	return url, nil
+}
+
 func (ps *postgresStorage) Ping(ctx context.Context) error {
 	return ps.db.Ping(ctx)
 }
@@ -40,19 +100,15 @@ func (ps *postgresStorage) FindByID(id string) (model.URL, error) {
 	ctx := context.Background()
 
 	const query = `
-		SELECT id, user_id, address, status, checked_at, updated_at, created_at
+		SELECT ` + urlColumns + `
 		FROM urls
 		WHERE id = $1
 	`
 
-	var url model.URL
-	err := ps.db.QueryRow(ctx, query, id).Scan(
-		&url.ID, &url.UserID, &url.Address, &url.Status, &url.CheckedAt,
-	)
-
+	url, err := scanURL(ps.db.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return model.URL{}, fmt.Errorf("url not found: %w", err)
+			return model.URL{}, appErr.ErrNotFound
 		}
 		return model.URL{}, fmt.Errorf("find by id failed: %w", err)
 	}
@@ -60,42 +116,11 @@ func (ps *postgresStorage) FindByID(id string) (model.URL, error) {
 	return url, nil
 }
 
-func (ps *postgresStorage) FindAll() ([]model.URL, error) {
-	ctx := context.Background()
-
-	const query = `
-		SELECT id, user_id, address, status, checked_at
-		FROM urls
-	`
-
-	rows, err := ps.db.Query(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("query failed: %w", err)
-	}
-	defer rows.Close()
-
-	var urls []model.URL
-
-	for rows.Next() {
-		var url model.URL
-		if err := rows.Scan(&url.ID, &url.UserID, &url.Address, &url.Status, &url.CheckedAt); err != nil {
-			return nil, fmt.Errorf("scan failed: %w", err)
-		}
-		urls = append(urls, url)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("row iteration failed: %w", err)
-	}
-
-	return urls, nil
-}
-
 func (ps *postgresStorage) FindAllByUserID(ctx context.Context, userID string) ([]model.URL, error) {
 	const query = `
-		SELECT id, user_id, address, status, checked_at
+		SELECT ` + urlColumns + `
 		from urls
-		where user_id = $1
+		where user_id = $1 AND deleted_at IS NULL
 	`
 	rows, err := ps.db.Query(ctx, query, userID)
 	if err != nil {
@@ -106,8 +131,8 @@ func (ps *postgresStorage) FindAllByUserID(ctx context.Context, userID string) (
 	var urls []model.URL
 
 	for rows.Next() {
-		var url model.URL
-		if err := rows.Scan(&url.ID, &url.UserID, &url.Address, &url.Status, &url.CheckedAt); err != nil {
+		url, err := scanURL(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan failed %w", err)
 		}
 		urls = append(urls, url)
@@ -120,26 +145,158 @@ func (ps *postgresStorage) FindAllByUserID(ctx context.Context, userID string) (
 	return urls, nil
 
 }
+
+// insertURLQuery inserts a URL with insertURLArgs
+const insertURLQuery = `
+		INSERT INTO urls(id, user_id, address, status, checked_at,
+			method, headers, body, timeout_ms, interval_ms, assertions,
+			failure_threshold, recovery_threshold, flap_threshold, flap_window_ms, check_state,
+			type, tcp, dns, tls, grpc, heartbeat, transaction, content, credential_id, transport, retry,
+			name, tags, labels, group_name, external_key, managed, drifted)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24,
+			NULLIF($25, ''), $26, $27, $28, COALESCE($29::text[], '{}'), COALESCE($30::jsonb, '{}'), $31,
+			$32, $33, $34)
+		RETURNING id, created_at, updated_at
+	`
+
+func insertURLArgs(url *model.URL) []any {
+	return []any{
+		url.ID, url.UserID, url.Address, url.Status, url.CheckedAt,
+		url.Method, url.Headers, url.Body, url.Timeout.Std().Milliseconds(), url.Interval.Std().Milliseconds(),
+		url.Assertions,
+		url.FailureThreshold, url.RecoveryThreshold, url.FlapThreshold, url.FlapWindow.Std().Milliseconds(), url.State,
+		url.Type, url.TCP, url.DNS, url.TLS, url.GRPC, url.Heartbeat, url.Transaction, url.Content, url.CredentialID, url.Transport, url.Retry,
+		url.Name, url.Tags, url.Labels, url.Group, url.Key, url.Managed, url.Drifted,
+	}
+}
+
+// isUniqueViolation reports whether a write failed on a unique constraint
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// saveError maps a failed insert to ErrConflict when it broke a unique constraint
+func saveError(err error) error {
+	if isUniqueViolation(err) {
+		return appErr.ErrConflict
+	}
+	return fmt.Errorf("failed to save URL: %w", err)
+}
+
 func (ps *postgresStorage) Save(url *model.URL) error {
 	ctx := context.Background()
 
-	const queryStr = `
-		INSERT INTO urls(id, user_id, address, status, checked_at)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
+	err := ps.db.QueryRow(ctx, insertURLQuery, insertURLArgs(url)...).Scan(&url.ID, &url.CreatedAt, &url.UpdatedAt)
+	if err != nil {
+		return saveError(err)
+	}
+
+	return nil
+}
+
+// updateURLQuery replaces the check spec of a live URL with updateURLArgs.
+// Status, check state and the certificate seen are the checker's and stay as
+// they are; the content baseline, and its hashes in the check state, are
+// dropped when the address changes or content checks are turned off.
+const updateURLQuery = `
+		UPDATE urls
+		SET content_baseline = CASE WHEN address = $2 AND $20::jsonb IS NOT NULL THEN content_baseline END,
+			content_changed = CASE WHEN address = $2 AND $20::jsonb IS NOT NULL THEN content_changed END,
+			check_state = CASE WHEN address = $2 AND $20::jsonb IS NOT NULL THEN check_state
+				ELSE check_state - 'content_baseline' - 'content_changed' END,
+			address = $2, method = $3, headers = $4, body = $5, timeout_ms = $6, interval_ms = $7, assertions = $8,
+			failure_threshold = $9, recovery_threshold = $10, flap_threshold = $11, flap_window_ms = $12,
+			type = $13, tcp = $14, dns = $15, tls = $16, grpc = $17, heartbeat = $18, transaction = $19, content = $20,
+			credential_id = NULLIF($21, ''), transport = $22, retry = $23,
+			name = $24, tags = COALESCE($25::text[], '{}'), labels = COALESCE($26::jsonb, '{}'), group_name = $27,
+			external_key = $28, managed = $29, drifted = $30,
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING updated_at
 	`
 
-	err := ps.db.QueryRow(ctx, queryStr, url.ID, url.UserID, url.Address, url.Status, url.CheckedAt).Scan(&url.ID)
+func updateURLArgs(url *model.URL) []any {
+	return []any{
+		url.ID, url.Address, url.Method, url.Headers, url.Body,
+		url.Timeout.Std().Milliseconds(), url.Interval.Std().Milliseconds(), url.Assertions,
+		url.FailureThreshold, url.RecoveryThreshold, url.FlapThreshold, url.FlapWindow.Std().Milliseconds(),
+		url.Type, url.TCP, url.DNS, url.TLS, url.GRPC, url.Heartbeat, url.Transaction, url.Content,
+		url.CredentialID, url.Transport, url.Retry,
+		url.Name, url.Tags, url.Labels, url.Group, url.Key, url.Managed, url.Drifted,
+	}
+}
+
+// Update replaces the check spec of a live URL, as updateURLQuery describes.
+// It fails with ErrConflict when the new key is taken.
+func (ps *postgresStorage) Update(ctx context.Context, url *model.URL) error {
+	err := ps.db.QueryRow(ctx, updateURLQuery, updateURLArgs(url)...).Scan(&url.UpdatedAt)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			if pgErr.Code == "23505" { // unique_violation
-				return appErr.ErrConflict
-			}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return appErr.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return appErr.ErrConflict
 		}
-		return fmt.Errorf("failed to save URL: %w", err)
+		return fmt.Errorf("failed to update URL: %w", err)
 	}
+	return nil
+}
+
+// SetPaused pauses or resumes a live URL. A resumed heartbeat monitor's next
+// ping is due one period from now, not from before the pause.
+func (ps *postgresStorage) SetPaused(ctx context.Context, id string, paused bool) error {
+	const query = `
+		UPDATE urls
+		SET paused = $2,
+			checked_at = CASE WHEN NOT $2 AND paused AND type = 'heartbeat' THEN NOW() ELSE checked_at END,
+			last_ping_at = CASE WHEN NOT $2 AND paused AND type = 'heartbeat' THEN NOW() ELSE last_ping_at END,
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
 
+	cmdTags, err := ps.db.Exec(ctx, query, id, paused)
+	if err != nil {
+		return fmt.Errorf("failed to set paused: %w", err)
+	}
+	if cmdTags.RowsAffected() == 0 {
+		return appErr.ErrNotFound
+	}
+	return nil
+}
+
+const softDeleteQuery = `UPDATE urls SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+
+// SoftDelete marks a live URL deleted. It bumps updated_at so checkers drop it
+// on their next sync.
+func (ps *postgresStorage) SoftDelete(ctx context.Context, id string) error {
+	cmdTags, err := ps.db.Exec(ctx, softDeleteQuery, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete URL: %w", err)
+	}
+	if cmdTags.RowsAffected() == 0 {
+		return appErr.ErrNotFound
+	}
+	return nil
+}
+
+// Restore brings back a soft-deleted URL
+func (ps *postgresStorage) Restore(ctx context.Context, id string) error {
+	const query = `
+		UPDATE urls
+		SET deleted_at = NULL,
+			checked_at = CASE WHEN type = 'heartbeat' THEN NOW() ELSE checked_at END,
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	cmdTags, err := ps.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore URL: %w", err)
+	}
+	if cmdTags.RowsAffected() == 0 {
+		return appErr.ErrNotFound
+	}
 	return nil
 }
 
@@ -147,8 +304,9 @@ func (ps *postgresStorage) UpdateStatus(id string, status string, checkedAt time
 	ctx := context.Background()
 	const query = `
 		UPDATE urls
-		SET status = $1, checked_at = $2
-		WHERE id = $3
+		SET status = $1, checked_at = $2,
+			status_changed_at = CASE WHEN status IS DISTINCT FROM $1 THEN $2 ELSE status_changed_at END
+		WHERE id = $3 AND deleted_at IS NULL
 	`
 
 	cmdTags, err := ps.db.Exec(ctx, query, status, checkedAt, id)
@@ -157,31 +315,74 @@ func (ps *postgresStorage) UpdateStatus(id string, status string, checkedAt time
 	}
 
 	if cmdTags.RowsAffected() == 0 {
-		return fmt.Errorf("no record found to update with id %s", id)
+		return appErr.ErrNotFound
 	}
 	return nil
 }
 
-func (ps *postgresStorage) FindByAddress(address string) (model.URL, error) {
-	ctx := context.Background()
+// AddressInUse reports whether a live URL of the user other than exceptID has the address
+func (ps *postgresStorage) AddressInUse(ctx context.Context, userID, address, exceptID string) (bool, error) {
+	const query = `
+		SELECT EXISTS (
+			SELECT 1 FROM urls
+			WHERE user_id = $1 AND address = $2 AND deleted_at IS NULL AND id <> $3
+		)
+	`
+
+	var inUse bool
+	if err := ps.db.QueryRow(ctx, query, userID, address, exceptID).Scan(&inUse); err != nil {
+		return false, fmt.Errorf("failed to check address: %w", err)
+	}
+	return inUse, nil
+}
 
+// FindByHeartbeatToken returns the heartbeat monitor whose ping URL carries token
+func (ps *postgresStorage) FindByHeartbeatToken(ctx context.Context, token string) (model.URL, error) {
 	const query = `
-		SELECT id, user_id, address, status, checked_at
+		SELECT ` + urlColumns + `
 		FROM urls
-		WHERE address = $1
+		WHERE heartbeat IS NOT NULL AND heartbeat ->> 'token' = $1 AND deleted_at IS NULL
 	`
 
-	var url model.URL
-	err := ps.db.QueryRow(ctx, query, address).Scan(
-		&url.ID, &url.UserID, &url.Address, &url.Status, &url.CheckedAt,
-	)
-
+	url, err := scanURL(ps.db.QueryRow(ctx, query, token))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return model.URL{}, appErr.ErrNotFound
 		}
-		return model.URL{}, fmt.Errorf("find by address failed: %w", err)
+		return model.URL{}, fmt.Errorf("find by heartbeat token failed: %w", err)
 	}
 
 	return url, nil
 }
+
+// FindUpdatedSince returns URLs created or reconfigured at or after since, oldest change first.
+// updated_at is the writing transaction's start time, so a row can commit with a time
+// older than rows already returned: callers read again some way behind the newest
+// UpdatedAt they saw and skip versions they already applied.
+func (ps *postgresStorage) FindUpdatedSince(ctx context.Context, since time.Time) ([]model.URL, error) {
+	const query = `
+		SELECT ` + urlColumns + `
+		FROM urls
+		WHERE updated_at >= $1
+		ORDER BY updated_at
+	`
+
+	rows, err := ps.db.Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var urls []model.URL
+	for rows.Next() {
+		url, err := scanURL(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		urls = append(urls, url)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration failed: %w", err)
+	}
+	return urls, nil
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samims/hcaas/services/url/internal/model"
+)
+
+// FindStatusChanges returns the outcome in effect at from (the last result before
+// the window, if any) followed by every point in [from, to) where the outcome changed.
+// Only transitions leave the database, so long windows stay cheap.
+func (ps *postgresStorage) FindStatusChanges(ctx context.Context, urlID string, from, to time.Time) ([]model.StatusChange, error) {
+	const query = `
+		WITH seed AS (
+			SELECT checked_at, status, id
+			FROM check_results
+			WHERE url_id = $1 AND checked_at < $2
+			ORDER BY checked_at DESC, id DESC
+			LIMIT 1
+		), in_window AS (
+			SELECT checked_at, status, id
+			FROM check_results
+			WHERE url_id = $1 AND checked_at >= $2 AND checked_at < $3
+		), ordered AS (
+			SELECT checked_at, status,
+				LAG(status) OVER (ORDER BY checked_at, id) AS prev_status
+			FROM (SELECT * FROM seed UNION ALL SELECT * FROM in_window) AS results
+		)
+		SELECT checked_at, status
+		FROM ordered
+		WHERE prev_status IS NULL OR prev_status <> status
+		ORDER BY checked_at
+	`
+
+	rows, err := ps.db.Query(ctx, query, urlID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []model.StatusChange
+	for rows.Next() {
+		var c model.StatusChange
+		if err := rows.Scan(&c.At, &c.Status); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration failed: %w", err)
+	}
+	return changes, nil
+}
+
+// FindUserStatusChanges is FindStatusChanges for every live URL of the user at
+// once, keyed by URL ID. URLs without results in reach have no entry.
+func (ps *postgresStorage) FindUserStatusChanges(ctx context.Context, userID string, from, to time.Time) (map[string][]model.StatusChange, error) {
+	const query = `
+		WITH user_urls AS (
+			SELECT id FROM urls WHERE user_id = $1 AND deleted_at IS NULL
+		), seed AS (
+			SELECT last.*
+			FROM user_urls u
+			CROSS JOIN LATERAL (
+				SELECT url_id, checked_at, status, id
+				FROM check_results
+				WHERE url_id = u.id AND checked_at < $2
+				ORDER BY checked_at DESC, id DESC
+				LIMIT 1
+			) AS last
+		), in_window AS (
+			SELECT r.url_id, r.checked_at, r.status, r.id
+			FROM check_results r
+			JOIN user_urls u ON u.id = r.url_id
+			WHERE r.checked_at >= $2 AND r.checked_at < $3
+		), ordered AS (
+			SELECT url_id, checked_at, status,
+				LAG(status) OVER (PARTITION BY url_id ORDER BY checked_at, id) AS prev_status
+			FROM (SELECT * FROM seed UNION ALL SELECT * FROM in_window) AS results
+		)
+		SELECT url_id, checked_at, status
+		FROM ordered
+		WHERE prev_status IS NULL OR prev_status <> status
+		ORDER BY url_id, checked_at
+	`
+
+	rows, err := ps.db.Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	changes := map[string][]model.StatusChange{}
+	for rows.Next() {
+		var (
+			urlID string
+			c     model.StatusChange
+		)
+		if err := rows.Scan(&urlID, &c.At, &c.Status); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		changes[urlID] = append(changes[urlID], c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration failed: %w", err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	appErr "github.com/samims/hcaas/services/url/internal/errors"
+	"github.com/samims/hcaas/services/url/internal/labels"
+	"github.com/samims/hcaas/services/url/internal/model"
+	"github.com/samims/hcaas/services/url/internal/pagination"
+)
+
+// urlSort is how a URLQuery sort maps onto SQL: the column ordered by, how a
+// cursor value is parsed back into that column's type, and the value of the
+// last row for the next cursor
+type urlSort struct {
+	column string
+	parse  func(string) (any, error)
+	value  func(model.URL) string
+}
+
+func parseText(v string) (any, error) { return v, nil }
+
+func parseTime(v string) (any, error) { return time.Parse(time.RFC3339Nano, v) }
+
+func parseInt(v string) (any, error) { return strconv.ParseInt(v, 10, 64) }
+
+var urlSorts = map[string]urlSort{
+	model.URLSortCreated: {"created_at", parseTime, func(u model.URL) string { return u.CreatedAt.Format(time.RFC3339Nano) }},
+	model.URLSortName:    {"name", parseText, func(u model.URL) string { return u.Name }},
+	model.URLSortStatus:  {"status", parseText, func(u model.URL) string { return u.Status }},
+	model.URLSortLatency: {"latency_ms", parseInt, func(u model.URL) string {
+		return strconv.FormatInt(u.Latency.Std().Milliseconds(), 10)
+	}},
+	model.URLSortLastChange: {"status_changed_at", parseTime, func(u model.URL) string {
+		return u.StatusChangedAt.Format(time.RFC3339Nano)
+	}},
+}
+
+// likeEscaper escapes the LIKE wildcards in a search term
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// FindURLs returns a page of the user's live URLs matching q, paginated by
+// (sort column, id)
+func (ps *postgresStorage) FindURLs(ctx context.Context, userID string, q model.URLQuery) (model.Page[model.URL], error) {
+	var page model.Page[model.URL]
+
+	sortBy := q.Sort
+	if sortBy == "" {
+		sortBy = model.URLSortCreated
+	}
+	sort, ok := urlSorts[sortBy]
+	if !ok {
+		return page, appErr.NewInvalid("unsupported sort %q", q.Sort)
+	}
+
+	args := []any{userID}
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	where := []string{"user_id = $1", "deleted_at IS NULL"}
+	if len(q.Status) > 0 {
+		where = append(where, "status = ANY("+arg(q.Status)+")")
+	}
+	if len(q.Type) > 0 {
+		where = append(where, "type = ANY("+arg(q.Type)+")")
+	}
+	if len(q.Tags) > 0 {
+		where = append(where, "tags @> "+arg(q.Tags)+"::text[]")
+	}
+	if q.Group != "" {
+		where = append(where, "group_name = "+arg(q.Group))
+	}
+	for _, r := range q.Selector {
+		switch r.Op {
+		case labels.OpEquals:
+			where = append(where, "labels @> "+arg(map[string]string{r.Key: r.Value})+"::jsonb")
+		case labels.OpNotEquals:
+			where = append(where, "NOT labels @> "+arg(map[string]string{r.Key: r.Value})+"::jsonb")
+		case labels.OpExists:
+			where = append(where, "labels ? "+arg(r.Key))
+		case labels.OpNotExists:
+			where = append(where, "NOT labels ? "+arg(r.Key))
+		}
+	}
+	if q.Paused != nil {
+		where = append(where, "paused = "+arg(*q.Paused))
+	}
+	if !q.CheckedFrom.IsZero() {
+		where = append(where, "checked_at >= "+arg(q.CheckedFrom))
+	}
+	if !q.CheckedTo.IsZero() {
+		where = append(where, "checked_at < "+arg(q.CheckedTo))
+	}
+	if q.Search != "" {
+		pattern := arg("%" + likeEscaper.Replace(q.Search) + "%")
+		where = append(where, "(address ILIKE "+pattern+" OR name ILIKE "+pattern+")")
+	}
+
+	dir, cmp := "ASC", ">"
+	if q.Desc {
+		dir, cmp = "DESC", "<"
+	}
+	cursor, err := pagination.Decode(q.Cursor)
+	if err != nil {
+		return page, appErr.NewInvalid("%v", err)
+	}
+	if cursor != nil {
+		after, err := sort.parse(cursor.Value)
+		if err != nil {
+			return page, appErr.NewInvalid("malformed cursor")
+		}
+		where = append(where, fmt.Sprintf("(%s, id) %s (%s, %s)", sort.column, cmp, arg(after), arg(cursor.ID)))
+	}
+
+	limit := pagination.ClampLimit(q.Limit)
+	query := `
+		SELECT ` + urlColumns + `
+		FROM urls
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY ` + sort.column + ` ` + dir + `, id ` + dir + `
+		LIMIT ` + arg(limit+1)
+
+	rows, err := ps.db.Query(ctx, query, args...)
+	if err != nil {
+		return page, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	page.Items = []model.URL{}
+	for rows.Next() {
+		url, err := scanURL(rows)
+		if err != nil {
+			return page, fmt.Errorf("scan failed: %w", err)
+		}
+		page.Items = append(page.Items, url)
+	}
+	if err := rows.Err(); err != nil {
+		return page, fmt.Errorf("row iteration failed: %w", err)
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = pagination.Encode(pagination.Cursor{Value: sort.value(last), ID: last.ID})
+	}
+	return page, nil
+}